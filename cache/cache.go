@@ -23,6 +23,12 @@ func cacheDir() string {
 	return filepath.Join(os.TempDir(), os.ExpandEnv("gojekyll-$USER"))
 }
 
+// Dir returns the user-specific cache directory. Other packages use this to
+// keep files that should last across invocations.
+func Dir() string {
+	return cacheDir()
+}
+
 // Clear clears the cache. It's used for testing.
 func Clear() error {
 	return os.RemoveAll(cacheDir())
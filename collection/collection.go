@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/frontmatter"
 	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/renderers"
 	"github.com/osteele/gojekyll/utils"
@@ -17,9 +18,12 @@ type Collection struct {
 	Name     string
 	Metadata map[string]interface{}
 
-	cfg   *config.Config
-	pages []Page
-	site  Site
+	cfg          *config.Config
+	pages        []Page
+	files        []*pages.StaticFile // files without front matter
+	site         Site
+	schema       *frontmatter.Schema // nil if the collection doesn't have one
+	schemaErrors []error             // the pages that don't match the schema
 }
 
 // Site is the interface a site provides to its collections.
@@ -69,6 +73,16 @@ func (c *Collection) Pages() []Page {
 	return c.pages
 }
 
+// StaticFiles returns the collection's files that don't have front matter.
+// The site copies these to the destination if the collection is output.
+func (c *Collection) StaticFiles() []*pages.StaticFile {
+	return c.files
+}
+
+// SchemaErrors returns the ways in which the collection's pages don't match
+// its front matter schema.
+func (c *Collection) SchemaErrors() []error { return c.schemaErrors }
+
 // Render renders the collection's pages.
 func (c *Collection) Render() error {
 	for _, p := range c.Pages() {
@@ -88,7 +102,7 @@ func (c *Collection) ToLiquid() interface{} {
 		map[string]interface{}{
 			"label":              c.Name,
 			"docs":               c.pages,
-			"files":              []string{},
+			"files":              c.files,
 			"relative_directory": strings.TrimSuffix(c.PathPrefix(), "/"),
 			"directory":          c.AbsDir(),
 		}))
@@ -1,10 +1,13 @@
 package collection
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/renderers"
+	"github.com/osteele/liquid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,3 +64,97 @@ func Test_ReadPages(t *testing.T) {
 	require.Equal(t, pages[0], pages[1].FrontMatter()["previous"])
 	require.Equal(t, nil, pages[1].FrontMatter()["next"])
 }
+
+func TestReadPages_sorting(t *testing.T) {
+	names := func(ps []Page) (out []string) {
+		for _, p := range ps {
+			out = append(out, filepath.Base(p.Source()))
+		}
+		return
+	}
+	site := siteFake{config.FromString("source: testdata")}
+
+	c := New(site, "docs", map[string]interface{}{})
+	require.NoError(t, c.ReadPages())
+	require.Equal(t, []string{"a.md", "b.md", "c.md"}, names(c.Pages()))
+	require.NotContains(t, c.Pages()[0].FrontMatter(), "next")
+
+	c = New(site, "docs", map[string]interface{}{"sort_by": "weight", "output": true})
+	require.NoError(t, c.ReadPages())
+	pages := c.Pages()
+	require.Equal(t, []string{"b.md", "a.md", "c.md"}, names(pages))
+	require.Equal(t, nil, pages[0].FrontMatter()["previous"])
+	require.Equal(t, pages[1], pages[0].FrontMatter()["next"])
+	require.Equal(t, pages[1], pages[2].FrontMatter()["previous"])
+
+	c = New(site, "docs", map[string]interface{}{"order": []interface{}{"c.md", "a.md"}, "sort_by": "weight"})
+	require.NoError(t, c.ReadPages())
+	require.Equal(t, []string{"c.md", "a.md", "b.md"}, names(c.Pages()))
+
+	site = siteFake{config.FromString("source: testdata\nshow_drafts: true")}
+	c = New(site, "docs", map[string]interface{}{"sort_by": "weight"})
+	require.NoError(t, c.ReadPages())
+	pages = c.Pages()
+	require.Equal(t, []string{"b.md", "a.md", "d.md", "c.md"}, names(pages))
+	require.Equal(t, true, pages[2].FrontMatter()["draft"])
+}
+
+func TestReadPages_categoryDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"_posts/2017-08-01-top.md":              "---\n---\n",
+		"blog/news/_posts/2017-08-02-nested.md": "---\ncategories: extra news\n---\n",
+		"blog/_drafts/2017-08-03-draft.md":      "---\n---\n",
+		"_other/_posts/2017-08-04-ignored.md":   "---\n---\n",
+	} {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	}
+	site := siteFake{config.FromString("source: " + dir + "\nshow_drafts: true")}
+	c := New(site, "posts", map[string]interface{}{})
+	require.NoError(t, c.ReadPages())
+	pages := c.Pages()
+	require.Len(t, pages, 3)
+	categories := map[string][]string{}
+	for _, p := range pages {
+		categories[filepath.Base(p.Source())] = p.Categories()
+	}
+	require.Equal(t, map[string][]string{
+		"2017-08-01-top.md":    nil,
+		"2017-08-02-nested.md": {"blog", "extra", "news"},
+		"2017-08-03-draft.md":  {"blog"},
+	}, categories)
+
+	// front matter categories replace the default categories
+	site = siteFake{config.FromString("source: " + dir + "\nshow_drafts: true\n" +
+		"defaults: [{scope: {path: ''}, values: {categories: default}}]")}
+	c = New(site, "posts", map[string]interface{}{})
+	require.NoError(t, c.ReadPages())
+	categories = map[string][]string{}
+	for _, p := range c.Pages() {
+		categories[filepath.Base(p.Source())] = p.Categories()
+	}
+	require.Equal(t, map[string][]string{
+		"2017-08-01-top.md":    {"default"},
+		"2017-08-02-nested.md": {"blog", "extra", "news"},
+		"2017-08-03-draft.md":  {"blog", "default"},
+	}, categories)
+}
+
+func TestReadPages_staticFiles(t *testing.T) {
+	site := siteFake{config.FromString("source: testdata")}
+	c := New(site, "docs", map[string]interface{}{"output": true})
+	require.NoError(t, c.ReadPages())
+	require.Len(t, c.Pages(), 3)
+	files := c.StaticFiles()
+	require.Len(t, files, 1)
+	require.Equal(t, "/docs/images/diagram.png", files[0].URL())
+	out, err := liquid.NewEngine().ParseAndRenderString(`{% for f in c.files %}{{ f.path }} {{ f.collection }}{% endfor %}`, liquid.Bindings{"c": c})
+	require.NoError(t, err)
+	require.Equal(t, "/docs/images/diagram.png docs", out)
+
+	c = New(site, "docs", map[string]interface{}{"permalink": "/:collection/:path/"})
+	require.NoError(t, c.ReadPages())
+	require.Equal(t, "/docs/images/diagram.png", c.StaticFiles()[0].URL())
+}
@@ -3,29 +3,132 @@ package collection
 import (
 	"os"
 	"path/filepath"
-	"sort"
+	"strings"
 
+	"github.com/osteele/gojekyll/frontmatter"
 	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/utils"
 )
 
 // ReadPages scans the file system for collection pages, and adds them to c.Pages.
 func (c *Collection) ReadPages() error {
-	if c.IsPostsCollection() && c.cfg.Drafts {
-		if err := c.scanDirectory(draftsPath); err != nil {
+	if err := c.readSchema(); err != nil {
+		return err
+	}
+	dirs, err := c.directories()
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if err := c.scanDirectory(dir.path, dir.drafts); err != nil {
 			return err
 		}
 	}
-	if err := c.scanDirectory(c.PathPrefix()); err != nil {
-		return err
+	c.sortPages()
+	if c.hasPrevNext() {
+		addPrevNext(c.pages)
+	}
+	return nil
+}
+
+type collectionDir struct {
+	path   string // relative to the site source
+	drafts bool
+}
+
+// directories returns the directories to scan: the collection directory, and
+// its drafts directory if drafts are on.
+//
+// Posts can also be in the _posts and _drafts directories of ordinary
+// directories, e.g. blog/_posts. These posts are in the categories named by
+// the path, e.g. "blog".
+func (c *Collection) directories() ([]collectionDir, error) {
+	if !c.IsPostsCollection() {
+		dirs := []collectionDir{{c.PathPrefix(), false}}
+		if c.cfg.Drafts {
+			dirs = append([]collectionDir{{c.draftsPath(), true}}, dirs...)
+		}
+		return dirs, nil
 	}
+	var dirs []collectionDir
+	sitePath := c.cfg.Source
+	err := filepath.Walk(sitePath, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel := utils.MustRel(sitePath, filename)
+		switch {
+		case !info.IsDir(), rel == ".":
+			return nil
+		case info.Name() == draftsPath:
+			if c.cfg.Drafts {
+				dirs = append(dirs, collectionDir{rel, true})
+			}
+			return filepath.SkipDir
+		case info.Name() == "_"+postsName:
+			dirs = append(dirs, collectionDir{rel, false})
+			return filepath.SkipDir
+		case strings.HasPrefix(info.Name(), "_"), c.site.Exclude(rel):
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// draftsPath returns the site-relative drafts directory. The posts drafts
+// are in the top-level _drafts; other collections' are inside the collection
+// directory.
+func (c *Collection) draftsPath() string {
 	if c.IsPostsCollection() {
-		sort.Sort(pagesByDate{c.pages})
+		return draftsPath
+	}
+	return filepath.Join(c.PathPrefix(), draftsPath)
+}
+
+// hasPrevNext returns true if the collection's pages have previous and next
+// links.
+func (c *Collection) hasPrevNext() bool {
+	return c.IsPostsCollection() || c.Output()
+}
+
+// RemovePage removes p from the collection. It returns the pages whose
+// previous or next links changed as a result.
+func (c *Collection) RemovePage(p Page) []Page {
+	for i, q := range c.pages {
+		if q != p {
+			continue
+		}
+		c.pages = append(c.pages[:i:i], c.pages[i+1:]...)
+		if !c.hasPrevNext() {
+			return nil
+		}
 		addPrevNext(c.pages)
+		var neighbors []Page
+		if i > 0 {
+			neighbors = append(neighbors, c.pages[i-1])
+		}
+		if i < len(c.pages) {
+			neighbors = append(neighbors, c.pages[i])
+		}
+		return neighbors
 	}
 	return nil
 }
 
+// RemoveStaticFile removes f from the collection's static files.
+func (c *Collection) RemoveStaticFile(f *pages.StaticFile) {
+	for i, g := range c.files {
+		if g == f {
+			c.files = append(c.files[:i:i], c.files[i+1:]...)
+			return
+		}
+	}
+}
+
 func addPrevNext(ps []Page) {
 	const prevPageField = "previous"
 	const nextPageField = "next"
@@ -44,10 +147,13 @@ func addPrevNext(ps []Page) {
 
 // scanDirectory scans the file system for collection pages, and adds them to c.Pages.
 //
-// This function is distinct from ReadPages so that the posts collection can call it twice.
-func (c *Collection) scanDirectory(dirname string) error {
-	sitePath := c.cfg.Source
-	dir := filepath.Join(sitePath, dirname)
+// This function is distinct from ReadPages so that it can also scan the
+// drafts directory, and the posts directories in category directories.
+func (c *Collection) scanDirectory(dirname string, drafts bool) error {
+	var (
+		sitePath = c.cfg.Source
+		dir      = filepath.Join(sitePath, dirname)
+	)
 	return filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
 		if err != nil {
 			if os.IsNotExist(err) {
@@ -55,19 +161,24 @@ func (c *Collection) scanDirectory(dirname string) error {
 			}
 			return err
 		}
-		siteRel := utils.MustRel(sitePath, filename)
+		rel := utils.MustRel(dir, filename)
 		switch {
+		case info.IsDir() && !drafts && utils.MustRel(sitePath, filename) == c.draftsPath():
+			return filepath.SkipDir
 		case info.IsDir():
 			return nil
-		case c.site.Exclude(siteRel):
+		// Site.Exclude excludes _underscore directories below the top
+		// level, such as _docs/_drafts and blog/_posts. Test the path as
+		// though it were in the top-level collection directory instead.
+		case c.site.Exclude(filepath.Join(c.PathPrefix(), rel)):
 			return nil
 		default:
-			return c.readPost(filename, utils.MustRel(dir, filename))
+			return c.readPost(filename, rel, drafts)
 		}
 	})
 }
 
-func (c *Collection) readPost(path string, rel string) error {
+func (c *Collection) readPost(path string, rel string, draft bool) error {
 	siteRel := utils.MustRel(c.cfg.Source, path)
 	strategy := c.strategy()
 	switch {
@@ -81,15 +192,35 @@ func (c *Collection) readPost(path string, rel string) error {
 		"permalink":  c.PermalinkPattern(),
 	}.Merged(c.cfg.GetFrontMatterDefaults(c.Name, siteRel))
 	strategy.parseFilename(rel, fm)
+	if draft {
+		fm["draft"] = true
+	}
 	f, err := pages.NewFile(c.site, path, filepath.ToSlash(rel), fm)
 	switch {
 	case err != nil:
 		return err
 	case f.IsStatic():
-		return nil
+		c.files = append(c.files, f.(*pages.StaticFile))
 	case f.Published() || c.cfg.Unpublished:
 		p := f.(Page) // f.Static() guarantees this
 		c.pages = append(c.pages, p)
+		if c.schema != nil {
+			for _, err := range c.schema.Validate(p.FrontMatter()) {
+				c.schemaErrors = append(c.schemaErrors, utils.WrapPathError(err, path))
+			}
+		}
 	}
 	return nil
 }
+
+// readSchema reads the collection's front matter schema: its schema
+// metadata, or else the collection's file in the _schemas directory.
+func (c *Collection) readSchema() error {
+	var err error
+	if v, ok := c.Metadata["schema"]; ok {
+		c.schema, err = frontmatter.NewSchema(v)
+		return utils.WrapError(err, "the "+c.Name+" collection")
+	}
+	c.schema, err = frontmatter.ReadSchemaFile(c.cfg.SchemaFile(c.Name))
+	return err
+}
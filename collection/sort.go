@@ -1,5 +1,133 @@
 package collection
 
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sortPages orders the collection's pages. The `order` list in the
+// collection metadata takes precedence over `sort_by`. Without either, posts
+// are ordered by date, and other documents by path.
+func (c *Collection) sortPages() {
+	switch {
+	case c.Metadata["order"] != nil:
+		sort.SliceStable(c.pages, pagesByOrder{c, c.orderIndex()}.less)
+	case getString(c.Metadata, "sort_by", "") != "":
+		sort.SliceStable(c.pages, pagesByField{c.pages, getString(c.Metadata, "sort_by", "")}.less)
+	case c.IsPostsCollection():
+		sort.Sort(pagesByDate{c.pages})
+	default:
+		sort.SliceStable(c.pages, func(i, j int) bool {
+			return c.pages[i].Source() < c.pages[j].Source()
+		})
+	}
+}
+
+// orderIndex returns a map from the filenames in the collection's `order`
+// list to their positions.
+func (c *Collection) orderIndex() map[string]int {
+	index := map[string]int{}
+	if list, ok := c.Metadata["order"].([]interface{}); ok {
+		for i, item := range list {
+			if name, ok := item.(string); ok {
+				index[filepath.ToSlash(filepath.Clean(name))] = i
+			}
+		}
+	}
+	return index
+}
+
+// pagesByOrder sorts the pages in the `order` list first, in that order;
+// then the others, by path.
+type pagesByOrder struct {
+	c     *Collection
+	index map[string]int
+}
+
+func (p pagesByOrder) less(i, j int) bool {
+	a, b := p.c.pages[i], p.c.pages[j]
+	ai, aListed := p.index[p.c.pagePath(a)]
+	bi, bListed := p.index[p.c.pagePath(b)]
+	switch {
+	case aListed && bListed:
+		return ai < bi
+	case aListed != bListed:
+		return aListed
+	default:
+		return a.Source() < b.Source()
+	}
+}
+
+// pagePath returns the slash-separated path of p relative to the collection
+// directory. A draft's path is relative to the drafts directory.
+func (c *Collection) pagePath(p Page) string {
+	for _, dir := range []string{c.draftsPath(), c.PathPrefix()} {
+		rel, err := filepath.Rel(filepath.Join(c.cfg.Source, dir), p.Source())
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(p.Source())
+}
+
+// pagesByField sorts the pages with a front matter field first, by its
+// value; then the others, by path.
+type pagesByField struct {
+	pages []Page
+	field string
+}
+
+func (p pagesByField) less(i, j int) bool {
+	a, b := p.pages[i], p.pages[j]
+	av, aHas := a.FrontMatter()[p.field]
+	bv, bHas := b.FrontMatter()[p.field]
+	aHas, bHas = aHas && av != nil, bHas && bv != nil
+	switch {
+	case aHas && bHas && !valuesEqual(av, bv):
+		return lessValue(av, bv)
+	case aHas != bHas:
+		return aHas
+	default:
+		return a.Source() < b.Source()
+	}
+}
+
+// lessValue compares front matter values: times, numbers, and otherwise
+// their string representations.
+func lessValue(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			return at.Before(bt)
+		}
+	}
+	if an, ok := toFloat(a); ok {
+		if bn, ok := toFloat(b); ok {
+			return an < bn
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func valuesEqual(a, b interface{}) bool {
+	return !lessValue(a, b) && !lessValue(b, a)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
 type pagesByDate struct{ pages []Page }
 
 // Len is part of sort.Interface.
@@ -17,7 +17,7 @@ func benchmarkCommand() (err error) {
 	var samples []float64
 	for i := 0; time.Since(startTime) < 10*time.Second; i++ {
 		sampleStart := time.Now()
-		site, err := loadSite(*source, options)
+		site, err := loadSite(*source, options, false)
 		if err != nil {
 			return err
 		}
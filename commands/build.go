@@ -20,6 +20,14 @@ func init() {
 func buildCommand(site *site.Site) error {
 	watch := site.Config().Watch
 
+	if err := site.FrontMatterErrors(); err != nil {
+		err = fmt.Errorf("front matter doesn't match the schema:\n%w", err)
+		if !watch {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, err)
+	}
+
 	logger.path("Destination:", site.DestDir())
 	logger.label("Generating...", "")
 	count, err := site.Write()
@@ -33,8 +41,9 @@ func buildCommand(site *site.Site) error {
 		return err
 	}
 
-	// FIXME the watch will miss files that changed during the first build
-
+	// loadSite started the watcher before the first build, so WatchRebuild
+	// replays changes made during it.
+	//
 	// server watch is implemented inside Server.Run, in contrast to this command
 	if watch {
 		events, err := site.WatchRebuild()
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/site"
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var configCmd = app.Command("config", "Print the merged site configuration, and where each value came from")
+var jsonConfig = configCmd.Flag("json", "Output the configuration in JSON format").Bool()
+
+// configCommand reads the configuration, but not the site files.
+func configCommand() error {
+	s, err := site.FromDirectory(*source, options)
+	if err != nil {
+		return err
+	}
+	if err := s.ReadConfig(); err != nil {
+		return err
+	}
+	var (
+		cfg  = s.Config()
+		vars = cfg.EffectiveVariables()
+	)
+	if *jsonConfig {
+		type annotated struct {
+			Value interface{} `json:"value"`
+			Layer string      `json:"layer"`
+			File  string      `json:"file,omitempty"`
+		}
+		out := map[string]annotated{}
+		for _, item := range vars {
+			key := fmt.Sprint(item.Key)
+			src := relativeSource(s, cfg.KeySource(key))
+			out[key] = annotated{utils.ConvertYAMLValue(item.Value), src.Layer, src.File}
+		}
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	// Print each top-level variable as YAML, with its source in a comment on
+	// its first line.
+	for _, item := range vars {
+		b, err := yaml.Marshal(yaml.MapSlice{item})
+		if err != nil {
+			return err
+		}
+		lines := strings.SplitN(strings.TrimSuffix(string(b), "\n"), "\n", 2)
+		lines[0] += "  # " + relativeSource(s, cfg.KeySource(fmt.Sprint(item.Key))).String()
+		fmt.Println(strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// relativeSource returns src with its file relative to the site source
+// directory, if it's inside it.
+func relativeSource(s *site.Site, src config.Source) config.Source {
+	if src.File != "" {
+		if rel, err := filepath.Rel(s.AbsDir(), utils.MustAbs(src.File)); err == nil && !strings.HasPrefix(rel, "..") {
+			src.File = rel
+		}
+	}
+	return src
+}
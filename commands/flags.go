@@ -13,6 +13,7 @@ var (
 	environment = ""
 	adminFile   = ""
 	configFiles = ""
+	envAllow    []string
 )
 
 var (
@@ -32,10 +33,13 @@ func init() {
 	_ = app.Flag("verbose", "Print verbose output.").Short('V').Action(boolVar("verbose", &options.Verbose)).Bool()
 	app.Flag("env", "Environment to use from _admin.yml (e.g. prod, stg, dev). If omitted and _admin.yml exists, uses base config.").StringVar(&environment)
 	app.Flag("admin", "Path to admin configuration file (e.g. _admin.yml). When specified, does not fall back to _config.yml.").StringVar(&adminFile)
+	_ = app.Flag("safe", "Safe mode: the configuration can only read the environment variables that --env-allow lists.").Action(boolVar("safe", &options.Safe)).Bool()
+	app.Flag("env-allow", "An environment variable that the configuration can read in safe mode. Repeat, or separate with commas.").StringsVar(&envAllow)
 	app.Flag("config", "Comma-separated list of configuration files (e.g. '_config.yml,_config.local.yml'). Later files override earlier ones.").StringVar(&configFiles)
 
 	// these flags are just present on build and serve, but I don't see a DRY way to say this
 	app.Flag("incremental", "Enable incremental rebuild.").Short('I').Action(boolVar("incremental", &options.Incremental)).Bool()
+	app.Flag("lsi", "Use TF-IDF similarity to find related posts.").Action(boolVar("lsi", &options.LSI)).Bool()
 	app.Flag("force_polling", "Force watch to use polling").BoolVar(&options.ForcePolling)
 
 	// --watch has different defaults for build and serve
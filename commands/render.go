@@ -11,7 +11,7 @@ var render = app.Command("render", "Render a file or URL path to standard output
 var renderPath = render.Arg("PATH", "Path or URL").String()
 
 func renderCommand(site *site.Site) error {
-	p, err := pageFromPathOrRoute(site, *renderPath)
+	p, err := site.PathDocument(*renderPath)
 	if err != nil {
 		return err
 	}
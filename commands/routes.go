@@ -13,15 +13,8 @@ var dynamicRoutes = routes.Flag("dynamic", "Only show routes to non-static files
 var jsonRoutes = routes.Flag("json", "Output routes in JSON format").Bool()
 
 func routesCommand(site *site.Site) error {
+	routeMap := site.RouteTable(*dynamicRoutes)
 	if *jsonRoutes {
-		// Create a map for JSON output
-		routeMap := make(map[string]string)
-		for u, p := range site.Routes {
-			if !*dynamicRoutes || !p.IsStatic() {
-				routeMap[u] = p.Source()
-			}
-		}
-		
 		// Output JSON
 		jsonData, err := json.MarshalIndent(routeMap, "", "  ")
 		if err != nil {
@@ -32,15 +25,12 @@ func routesCommand(site *site.Site) error {
 		// Original text output
 		logger.label("Routes:", "")
 		var urls []string
-		for u, p := range site.Routes {
-			if !*dynamicRoutes || !p.IsStatic() {
-				urls = append(urls, u)
-			}
+		for u := range routeMap {
+			urls = append(urls, u)
 		}
 		sort.Strings(urls)
 		for _, u := range urls {
-			filename := site.Routes[u].Source()
-			fmt.Printf("  %s -> %s\n", u, filename)
+			fmt.Printf("  %s -> %s\n", u, routeMap[u])
 		}
 	}
 	return nil
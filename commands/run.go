@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime/pprof"
+	"sort"
+	"strings"
 
 	kingpin "github.com/alecthomas/kingpin/v2"
 	"github.com/osteele/gojekyll/config"
@@ -30,6 +32,10 @@ func ParseAndRun(args []string) error {
 	options.Environment = environment
 	options.AdminFile = adminFile
 	options.ConfigFiles = configFiles
+	options.EnvAllowlist = nil
+	for _, s := range envAllow {
+		options.EnvAllowlist = append(options.EnvAllowlist, strings.Split(s, ",")...)
+	}
 	return run(cmd)
 }
 
@@ -46,9 +52,13 @@ func run(cmd string) error { // nolint: gocyclo
 		return pluginsCommand()
 	case versionCmd.FullCommand():
 		return versionCommand()
+	case themeFetch.FullCommand():
+		return themeFetchCommand()
+	case configCmd.FullCommand():
+		return configCommand()
 	}
 
-	site, err := loadSite(*source, options)
+	site, err := loadSite(*source, options, cmd == build.FullCommand() && options.Watch)
 	// Print the version at an awkward place, so its
 	// labels will line up. And print it even if
 	// loading the site produced an error.
@@ -81,11 +91,19 @@ func run(cmd string) error { // nolint: gocyclo
 }
 
 // Load the site, and print the common banner settings.
-func loadSite(source string, flags config.Flags) (*site.Site, error) {
+//
+// If watch is true, this starts the file watcher before it reads the site, so
+// that site.WatchRebuild sees changes made during the first build.
+func loadSite(source string, flags config.Flags, watch bool) (*site.Site, error) {
 	site, err := site.FromDirectory(source, flags)
 	if err != nil {
 		return nil, err
 	}
+	if watch {
+		if _, err := site.WatchFiles(); err != nil {
+			return nil, err
+		}
+	}
 	const configurationFileLabel = "Configuration file:"
 	if cf := site.Config().ConfigFile; cf != "" {
 		logger.path(configurationFileLabel, cf)
@@ -94,9 +112,31 @@ func loadSite(source string, flags config.Flags) (*site.Site, error) {
 	}
 	logger.path("Source:", site.SourceDir())
 	err = site.Read()
+	if err == nil && site.Config().Verbose {
+		err = logLayouts(site)
+	}
 	return site, err
 }
 
+// logLayouts prints the file that each layout comes from, which is a theme's
+// unless the site overrides it.
+func logLayouts(site *site.Site) error {
+	files, err := site.RendererManager().LayoutFiles()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	logger.label("Layouts:", "")
+	for _, name := range names {
+		logger.label("", "%s: %s", name, files[name])
+	}
+	return nil
+}
+
 func setupProfiling() func() {
 	profilePath := "gojekyll.prof"
 	logger.label("Profiling...", "")
@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -14,3 +17,53 @@ err := os.RemoveAll("testdata/site/_site")
 err = ParseAndRun([]string{"build", "-s", "testdata/site", "-q"})
 	require.NoError(t, err)
 }
+
+// captureStdout returns what f writes to the standard output.
+func captureStdout(t *testing.T, f func() error) string {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	err = f()
+	os.Stdout = stdout
+	require.NoError(t, w.Close())
+	require.NoError(t, err)
+	return <-out
+}
+
+func TestConfigCommand(t *testing.T) {
+	out := captureStdout(t, func() error {
+		return ParseAndRun([]string{"config", "-s", "testdata/site", "-q"})
+	})
+	require.Contains(t, out, "\ntitle: Site Title  # _config.yml (config file)\n")
+	require.Contains(t, out, "\nport: 4000  # default\n")
+
+	out = captureStdout(t, func() error {
+		return ParseAndRun([]string{"config", "-s", "testdata/site", "--json", "-q"})
+	})
+	var vars map[string]struct {
+		Value interface{}
+		Layer string
+		File  string
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &vars))
+	require.Equal(t, "Site Title", vars["title"].Value)
+	require.Equal(t, "config file", vars["title"].Layer)
+	require.Equal(t, "_config.yml", vars["title"].File)
+	require.Equal(t, "default", vars["port"].Layer)
+}
+
+func TestBuildCommand_frontMatterSchema(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "_schemas"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_schemas", "pages.yml"), []byte("required: [title]\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.md"), []byte("---\nlayout: none\n---\n"), 0644))
+	err := ParseAndRun([]string{"build", "-s", dir, "-d", filepath.Join(dir, "_site"), "-q"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "index.md: missing the required variable title")
+}
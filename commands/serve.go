@@ -10,6 +10,11 @@ var (
 	open  = serve.Flag("open-url", "Launch your site in a browser").Short('o').Bool()
 	_     = serve.Flag("host", "Host to bind to").Short('H').Action(stringVar("host", &options.Host)).String()
 	_     = serve.Flag("port", "Port to listen on").Short('P').Action(intVar("port", &options.Port)).Int()
+	_     = serve.Flag("ssl-cert", "SSL certificate file, relative to the source directory").Action(stringVar("ssl-cert", &options.SSLCert)).String()
+	_     = serve.Flag("ssl-key", "SSL private key file, relative to the source directory").Action(stringVar("ssl-key", &options.SSLKey)).String()
+	_     = serve.Flag("ssl-self-signed", "Serve HTTPS, with a generated and cached self-signed certificate").Action(boolVar("ssl-self-signed", &options.SSLSelfSigned)).Bool()
+	_     = serve.Flag("livereload-port", "Also serve Live Reload on this port, for clients that expect a separate port").Action(intVar("livereload-port", &options.LiveReloadPort)).Int()
+	_     = serve.Flag("debug-endpoints", "Serve site introspection pages under /__gojekyll/").Default("true").Action(boolVar("debug-endpoints", &options.DebugEndpoints)).Bool()
 )
 
 func serveCommand(site *site.Site) error {
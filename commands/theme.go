@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"github.com/osteele/gojekyll/plugins"
+	"github.com/osteele/gojekyll/site"
+)
+
+var themeCmd = app.Command("theme", "Manage the site theme")
+var themeFetch = themeCmd.Command("fetch", "Download the site's remote_theme into the offline cache")
+
+// themeFetchCommand reads the configuration, but not the site, since the
+// site can't be read until the theme is in the cache.
+func themeFetchCommand() error {
+	s, err := site.FromDirectory(*source, options)
+	if err != nil {
+		return err
+	}
+	remoteTheme, _ := s.Config().String("remote_theme")
+	logger.label("Remote theme:", "%s", remoteTheme)
+	dir, err := plugins.FetchRemoteTheme(s.Config())
+	if err != nil {
+		return err
+	}
+	logger.path("Cached in:", dir)
+	return nil
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/osteele/gojekyll/utils"
@@ -19,9 +21,11 @@ type Config struct {
 	DataDir     string                            `yaml:"data_dir"`
 	IncludesDir string                            `yaml:"includes_dir"`
 	Collections map[string]map[string]interface{} `yaml:"-"`
+	Taxonomies  map[string]Taxonomy               `yaml:"-"`
 	Theme       string
 
 	// Handling Reading
+	Safe        bool
 	Include     []string
 	Exclude     []string
 	KeepFiles   []string `yaml:"keep_files"`
@@ -38,16 +42,26 @@ type Config struct {
 	// Conversion
 	ExcerptSeparator string `yaml:"excerpt_separator"`
 	Incremental      bool
+	LSI              bool `yaml:"lsi"`
 	Sass             struct {
 		Dir string `yaml:"sass_dir"`
 		// TODO Style string // compressed
 	}
 
 	// Serving
-	Host        string
-	Port        int
-	AbsoluteURL string `yaml:"url"`
-	BaseURL     string
+	Host           string
+	Port           int
+	LiveReloadPort int    `yaml:"livereload_port"`
+	AbsoluteURL    string `yaml:"url"`
+	BaseURL        string
+	SSLCert        string `yaml:"ssl_cert"`
+	SSLKey         string `yaml:"ssl_key"`
+
+	// Localization
+	Languages               []string
+	DefaultLang             string   `yaml:"default_lang"`
+	ExcludeFromLocalization []string `yaml:"exclude_from_localization"`
+	ActiveLang              string   `yaml:"-"` // set on a language build
 
 	// Outputting
 	Permalink string
@@ -62,14 +76,22 @@ type Config struct {
 	}
 
 	// CLI-only
-	DryRun       bool `yaml:"-"`
-	ForcePolling bool `yaml:"-"`
-	Watch        bool `yaml:"-"`
+	DebugEndpoints bool `yaml:"-"`
+	DryRun         bool `yaml:"-"`
+	ForcePolling   bool `yaml:"-"`
+	SSLSelfSigned  bool `yaml:"-"`
+	Watch          bool `yaml:"-"`
 
 	// Meta
-	ConfigFile string                 `yaml:"-"`
-	m          map[string]interface{} `yaml:"-"` // config file, as map
-	ms         yaml.MapSlice          `yaml:"-"` // config file, as MapSlice
+	ConfigFile  string                 `yaml:"-"`
+	configPaths []string               `yaml:"-"` // the configuration files that were read
+	m           map[string]interface{} `yaml:"-"` // config file, as map
+	ms          yaml.MapSlice          `yaml:"-"` // config file, as MapSlice
+	sources     map[string]Source      `yaml:"-"` // top-level key -> where it was set
+	warnings    []error                `yaml:"-"` // from checking the configuration files
+	envSafe     *bool                  `yaml:"-"` // --safe, if set
+	envAllow    []string               `yaml:"-"` // --env-allow
+	envRefs     map[string]interface{} `yaml:"-"` // top-level key -> value before expandEnv
 
 	// Plugins
 	RequireFrontMatter        bool            `yaml:"-"`
@@ -78,17 +100,31 @@ type Config struct {
 
 // FromDirectory updates the config from the config file in
 // the directory, if such a file exists.
+//
+// It checks the configuration files against the schema. A value of the wrong
+// type is an error; an unknown variable is a warning, or with strict_config
+// an error if it's in a map that the schema knows all the variables of.
 func (c *Config) FromDirectory(dir string, environment string, adminFile string, configFiles string) error {
+	c.warnings = nil
+	if err := c.fromDirectory(dir, environment, adminFile, configFiles); err != nil {
+		return err
+	}
+	return c.checkWarnings()
+}
+
+func (c *Config) fromDirectory(dir string, environment string, adminFile string, configFiles string) error {
 	// Check JEKYLL_CONFIG environment variable if --config flag not provided
+	layer := ConfigFlagLayer
 	if configFiles == "" {
 		if jekyllConfig := os.Getenv("JEKYLL_CONFIG"); jekyllConfig != "" {
 			configFiles = jekyllConfig
+			layer = ConfigEnvLayer
 		}
 	}
 	
 	// If explicit config files are specified, use those and skip admin file logic
 	if configFiles != "" {
-		return c.loadConfigFiles(dir, configFiles)
+		return c.loadConfigFiles(dir, configFiles, layer)
 	}
 	// Determine admin file path
 	var adminPath string
@@ -113,7 +149,10 @@ func (c *Config) FromDirectory(dir string, environment string, adminFile string,
 				Site map[string]interface{} `yaml:"site"`
 			}
 			if err := yaml.Unmarshal(bytes, &adminConfig); err != nil {
-				return utils.WrapPathError(err, adminPath)
+				return utils.WrapParseError(err, adminPath, bytes)
+			}
+			if err := c.checkAdminFile(bytes, adminPath, environment); err != nil {
+				return err
 			}
 
 			// Get base and environment-specific config
@@ -149,6 +188,13 @@ func (c *Config) FromDirectory(dir string, environment string, adminFile string,
 				existingMS := c.ms
 				existingM := c.m
 
+				c.setAdminSources(baseConfig, AdminBaseLayer, adminPath)
+				if envConfig, ok := adminConfig.Site[environment].(map[interface{}]interface{}); ok && environment != "" {
+					c.setAdminSources(envConfig, "admin site."+environment, adminPath)
+				}
+				if mergedBytes, err = c.expandEnv(mergedBytes); err != nil {
+					return err
+				}
 				if err = Unmarshal(mergedBytes, c); err != nil {
 					return utils.WrapPathError(err, adminPath)
 				}
@@ -197,6 +243,7 @@ func (c *Config) FromDirectory(dir string, environment string, adminFile string,
 					}
 				}
 				c.ms = newMS
+				c.configPaths = append(c.configPaths, adminPath)
 				if environment != "" {
 					c.ConfigFile = adminPath + " (env: " + environment + ")"
 				} else {
@@ -208,6 +255,7 @@ func (c *Config) FromDirectory(dir string, environment string, adminFile string,
 				if jekyllURL := os.Getenv("JEKYLL_URL"); jekyllURL != "" {
 					c.AbsoluteURL = jekyllURL
 					c.Set("url", jekyllURL)
+					c.setSource("url", Source{Layer: URLEnvLayer})
 				}
 				
 				return nil
@@ -217,18 +265,21 @@ func (c *Config) FromDirectory(dir string, environment string, adminFile string,
 		return utils.WrapPathError(err, adminPath)
 	}
 
-	// Fall back to _config.yml (only if admin file was not explicitly specified)
-	path := filepath.Join(dir, "_config.yml")
-	bytes, err := os.ReadFile(path)
-	switch {
-	case os.IsNotExist(err):
-		// break
-	case err != nil:
-		return err
-	default:
+	// Fall back to _config.yml, or .yaml, .toml or .json (only if admin file
+	// was not explicitly specified)
+	if path := findConfigFile(dir); path != "" {
+		bytes, err := c.readConfigFile(path)
+		if err != nil {
+			return err
+		}
+		c.setSources(bytes, Source{Layer: ConfigFileLayer, File: path})
+		if bytes, err = c.expandEnv(bytes); err != nil {
+			return err
+		}
 		if err = Unmarshal(bytes, c); err != nil {
 			return utils.WrapPathError(err, path)
 		}
+		c.configPaths = append(c.configPaths, path)
 		c.ConfigFile = path
 	}
 	c.Source = dir
@@ -237,14 +288,16 @@ func (c *Config) FromDirectory(dir string, environment string, adminFile string,
 	if jekyllURL := os.Getenv("JEKYLL_URL"); jekyllURL != "" {
 		c.AbsoluteURL = jekyllURL
 		c.Set("url", jekyllURL)
+		c.setSource("url", Source{Layer: URLEnvLayer})
 	}
 	
 	return nil
 }
 
 // loadConfigFiles loads one or more config files separated by commas.
-// Later files override earlier ones.
-func (c *Config) loadConfigFiles(dir string, configFiles string) error {
+// Later files override earlier ones. layer is the configuration layer that
+// named the files.
+func (c *Config) loadConfigFiles(dir string, configFiles string, layer string) error {
 	// Split by comma and trim whitespace
 	files := strings.Split(configFiles, ",")
 	for i, f := range files {
@@ -258,8 +311,8 @@ func (c *Config) loadConfigFiles(dir string, configFiles string) error {
 	// Track config file names for display
 	configFileNames := []string{}
 	
-	// Merged YAML data
-	var mergedData map[string]interface{}
+	// Merged YAML data, in the order that the variables first appear
+	mergedData := yaml.MapSlice{}
 	
 	// Load and merge config files in order
 	for _, configFile := range files {
@@ -275,28 +328,29 @@ func (c *Config) loadConfigFiles(dir string, configFiles string) error {
 			configPath = filepath.Join(dir, configFile)
 		}
 		
-		// Read config file
-		bytes, err := os.ReadFile(configPath)
+		// Read config file, which can be YAML, TOML or JSON
+		bytes, err := c.readConfigFile(configPath)
 		if err != nil {
-			return utils.WrapPathError(err, configPath)
+			return err
 		}
 		
 		// Parse YAML into a map
-		var fileData map[string]interface{}
+		var fileData yaml.MapSlice
 		if err := yaml.Unmarshal(bytes, &fileData); err != nil {
 			return utils.WrapPathError(err, configPath)
 		}
 		
-		// Merge into accumulated data
-		if mergedData == nil {
-			mergedData = fileData
-		} else {
-			// Override with values from this file
-			for k, v := range fileData {
-				mergedData[k] = v
+		// Override with values from this file
+		for _, item := range fileData {
+			if i := mapSliceIndex(mergedData, item.Key); i >= 0 {
+				mergedData[i].Value = item.Value
+			} else {
+				mergedData = append(mergedData, item)
 			}
 		}
 		
+		c.setSources(bytes, Source{Layer: layer, File: configPath})
+		c.configPaths = append(c.configPaths, configPath)
 		configFileNames = append(configFileNames, configPath)
 	}
 	
@@ -305,6 +359,9 @@ func (c *Config) loadConfigFiles(dir string, configFiles string) error {
 	if err != nil {
 		return err
 	}
+	if mergedBytes, err = c.expandEnv(mergedBytes); err != nil {
+		return err
+	}
 	
 	if err = Unmarshal(mergedBytes, c); err != nil {
 		return err
@@ -322,6 +379,7 @@ func (c *Config) loadConfigFiles(dir string, configFiles string) error {
 	if jekyllURL := os.Getenv("JEKYLL_URL"); jekyllURL != "" {
 		c.AbsoluteURL = jekyllURL
 		c.Set("url", jekyllURL)
+		c.setSource("url", Source{Layer: URLEnvLayer})
 	}
 	
 	return nil
@@ -339,9 +397,20 @@ type collectionsMap struct {
 	Collections map[string]map[string]interface{}
 }
 
-// IsConfigPath returns true if its arguments is a site configuration file.
+// IsConfigPath returns true if its argument, a path relative to the site
+// source, is a site configuration file: one that the site read, or one that
+// it would read if it were created.
 func (c *Config) IsConfigPath(rel string) bool {
-	return rel == "_config.yml"
+	switch {
+	case utils.StringArrayContains(siteConfigNames, rel), rel == "_admin.yml":
+		return true
+	}
+	for _, path := range c.configPaths {
+		if utils.MustAbs(path) == utils.MustAbs(filepath.Join(c.Source, rel)) {
+			return true
+		}
+	}
+	return false
 }
 
 // SassDir returns the relative path of the SASS directory.
@@ -355,18 +424,70 @@ func (c *Config) SourceDir() string {
 }
 
 // GetFrontMatterDefaults implements https://jekyllrb.com/docs/configuration/#front-matter-defaults
+//
+// typename is "pages", "posts", or another collection name; or the empty
+// string for a static file outside a collection, which only typeless scopes
+// apply to. rel is the slash-separated path relative to the site source.
+//
+// A scope path that contains a "*" is a glob, where "**" matches any number
+// of directories. It applies to the files that it matches, and the files
+// inside the directories that it matches. Other scope paths are prefixes.
+//
+// Where scopes set the same variable, the most specific one wins: the one
+// with the longer path; then one with a type; then the later one.
 func (c *Config) GetFrontMatterDefaults(typename, rel string) (m map[string]interface{}) {
-	for _, entry := range c.Defaults {
+	rel = filepath.ToSlash(rel)
+	var matches []int
+	for i, entry := range c.Defaults {
 		scope := &entry.Scope
-		hasPrefix := strings.HasPrefix(rel, scope.Path)
+		hasPath := scopePathMatches(scope.Path, rel)
 		hasType := scope.Type == "" || scope.Type == typename
-		if hasPrefix && hasType {
-			m = utils.MergeStringMaps(m, entry.Values)
+		if hasPath && hasType {
+			matches = append(matches, i)
+		}
+	}
+	specificity := func(i int) (int, bool) {
+		scope := &c.Defaults[i].Scope
+		return len(cleanScopePath(scope.Path)), scope.Type != ""
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		li, ti := specificity(matches[i])
+		lj, tj := specificity(matches[j])
+		if li != lj {
+			return li < lj
 		}
+		return !ti && tj
+	})
+	for _, i := range matches {
+		m = utils.MergeStringMaps(m, c.Defaults[i].Values)
 	}
 	return
 }
 
+func cleanScopePath(p string) string {
+	p = strings.TrimPrefix(filepath.ToSlash(p), "./")
+	return strings.Trim(p, "/")
+}
+
+// scopePathMatches reports whether a front matter defaults scope path
+// applies to the slash-separated path rel.
+func scopePathMatches(scopePath, rel string) bool {
+	scopePath = cleanScopePath(scopePath)
+	switch {
+	case scopePath == "" || scopePath == ".":
+		return true
+	case !strings.Contains(scopePath, "*"):
+		return strings.HasPrefix(rel, scopePath)
+	}
+	// match rel, or a directory that contains it
+	for p := rel; p != "." && p != "/" && p != ""; p = path.Dir(p) {
+		if utils.MatchGlob(scopePath, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // RequiresFrontMatter returns a bool indicating whether the file requires front matter in order to recognize as a page.
 func (c *Config) RequiresFrontMatter(rel string) bool {
 	switch {
@@ -410,6 +531,7 @@ func Unmarshal(bytes []byte, c *Config) error {
 	if err := yaml.Unmarshal(bytes, &cMap); err == nil {
 		c.Collections = cMap.Collections
 	}
+	c.unmarshalTaxonomies(bytes)
 	if err := yaml.Unmarshal(bytes, &compat); err != nil {
 		return err
 	}
@@ -434,6 +556,7 @@ func (c *Config) Variables() map[string]interface{} {
 // This does not update the corresponding value in the Config struct.
 func (c *Config) Set(key string, val interface{}) {
 	c.m[key] = val
+	delete(c.envRefs, key)
 	for i := range c.ms {
 		if c.ms[i].Key == key {
 			c.ms[i].Value = val
@@ -39,9 +39,48 @@ func TestUnmarshal(t *testing.T) {
 	// fmt.Println(c.Collections)
 }
 
+func TestConfig_Taxonomies(t *testing.T) {
+	c := Default()
+	require.NoError(t, Unmarshal([]byte("taxonomies: [series]"), &c))
+	require.Contains(t, c.Taxonomies, "series")
+	require.Equal(t, "series", c.Taxonomies["series"].FieldName("series"))
+	require.Equal(t, []string{"posts"}, c.Taxonomies["series"].CollectionNames())
+
+	c = Default()
+	require.NoError(t, Unmarshal([]byte("taxonomies:\n  authors:\n    field: author\n    collections: [posts, books]\n"), &c))
+	require.Equal(t, "author", c.Taxonomies["authors"].FieldName("authors"))
+	require.Equal(t, []string{"posts", "books"}, c.Taxonomies["authors"].CollectionNames())
+}
+
 func TestConfig_IsMarkdown(t *testing.T) {
 	c := Default()
 	require.True(t, c.IsMarkdown("name.md"))
 	require.True(t, c.IsMarkdown("name.markdown"))
 	require.False(t, c.IsMarkdown("name.html"))
 }
+
+func TestConfig_GetFrontMatterDefaults(t *testing.T) {
+	c := Default()
+	require.NoError(t, Unmarshal([]byte(`
+defaults:
+  - scope: {path: ""}
+    values: {layout: default, sitemap: true}
+  - scope: {path: "docs/**/*.md"}
+    values: {layout: doc}
+  - scope: {path: "docs", type: pages}
+    values: {layout: docs-page, toc: true}
+  - scope: {path: "docs"}
+    values: {layout: docs}
+  - scope: {path: "assets/*.pdf"}
+    values: {sitemap: false}
+`), &c))
+	require.Equal(t, map[string]interface{}{"layout": "default", "sitemap": true}, c.GetFrontMatterDefaults("pages", "about.md"))
+	// the longest path wins
+	require.Equal(t, "doc", c.GetFrontMatterDefaults("pages", "docs/guide/intro.md")["layout"])
+	require.Equal(t, true, c.GetFrontMatterDefaults("pages", "docs/guide/intro.md")["toc"])
+	// on a tie, a typed scope wins
+	require.Equal(t, "docs-page", c.GetFrontMatterDefaults("pages", "docs/guide/intro.html")["layout"])
+	require.Equal(t, "docs", c.GetFrontMatterDefaults("", "docs/diagram.png")["layout"])
+	require.Equal(t, false, c.GetFrontMatterDefaults("", "assets/paper.pdf")["sitemap"])
+	require.Equal(t, true, c.GetFrontMatterDefaults("", "assets/sub/paper.pdf")["sitemap"])
+}
@@ -10,7 +10,7 @@ func Default() Config {
 
 // FromString returns a new configuration initialized from a string
 func FromString(src string) Config {
-	var c = Config{RequireFrontMatter: true}
+	var c = Config{RequireFrontMatter: true, DebugEndpoints: true}
 	// TODO this doesn't set c.Variables. Should it? If so,
 	// config.Unmarshal needs to merge them instead of overwriting them (unless yaml.Unmarshal already does this)
 	err := Unmarshal([]byte(src), &c)
@@ -29,6 +29,7 @@ destination:  ./_site
 layouts_dir:  _layouts
 data_dir:     _data
 includes_dir: _includes
+safe:         false
 collections:
   posts:
     output:   true
@@ -47,6 +48,7 @@ plugins:   []
 # Conversion
 excerpt_separator: "\n\n"
 incremental: false
+lsi: false
 
 # Serving
 detach:  false
@@ -10,15 +10,20 @@ type Flags struct {
 	// these are pointers so we can tell whether they've been set, and leave
 	// the config file alone if not
 	Destination, Host           *string
+	SSLCert, SSLKey             *string
 	Drafts, Future, Unpublished *bool
 	Incremental, Verbose        *bool
-	Port                        *int
+	LSI, Safe                   *bool
+	SSLSelfSigned               *bool
+	DebugEndpoints              *bool
+	Port, LiveReloadPort        *int
 
 	// these aren't in the config file, so make them actual values
 	DryRun, ForcePolling, Watch bool
 	Environment                 string
 	AdminFile                   string
 	ConfigFiles                 string
+	EnvAllowlist                []string
 }
 
 // ApplyFlags overwrites the configuration with values from flags.
@@ -39,6 +44,7 @@ func (c *Config) ApplyFlags(f Flags) {
 		configField := rd.FieldByName(field.Name)
 		if configField.IsValid() && configField.CanSet() {
 			configField.Set(val)
+			c.setFlagSource(field.Name)
 		}
 	}
 }
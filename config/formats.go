@@ -0,0 +1,199 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// siteConfigNames are the names of the site configuration file, in the
+// order that FromDirectory looks for them.
+var siteConfigNames = []string{"_config.yml", "_config.yaml", "_config.toml", "_config.json"}
+
+// findConfigFile returns the path of the configuration file in dir, or ""
+// if there isn't one.
+func findConfigFile(dir string) string {
+	for _, name := range siteConfigNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// readConfigFile reads a YAML, TOML or JSON configuration file, and checks
+// it against the schema. It returns YAML text, with the variables in the
+// order of the file, so that the rest of the configuration code needn't care
+// about the format.
+func (c *Config) readConfigFile(filename string) ([]byte, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, utils.WrapPathError(err, filename)
+	}
+	var ms yaml.MapSlice
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".toml":
+		ms, err = tomlMapSlice(b)
+		var tomlErr toml.ParseError
+		if errors.As(err, &tomlErr) {
+			return nil, utils.WrapPathLineError(errors.New(tomlErr.Message), filename, tomlErr.Position.Line)
+		}
+	case ".json":
+		ms, err = jsonMapSlice(b)
+		if err != nil {
+			return nil, utils.WrapParseError(err, filename, b)
+		}
+	default:
+		if err := yaml.Unmarshal(b, &ms); err != nil {
+			return nil, utils.WrapParseError(err, filename, b)
+		}
+		return b, c.checkConfigFile(ms, filename, b)
+	}
+	if err != nil {
+		return nil, utils.WrapPathError(err, filename)
+	}
+	if err := c.checkConfigFile(ms, filename, b); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(ms)
+}
+
+// tomlMapSlice decodes TOML into a MapSlice. The decoder returns maps, but
+// it also returns the keys in document order; these put the maps in order.
+func tomlMapSlice(b []byte) (yaml.MapSlice, error) {
+	var m map[string]interface{}
+	md, err := toml.Decode(string(b), &m)
+	if err != nil {
+		return nil, err
+	}
+	order := map[string][]string{} // table path -> keys, in order
+	for _, key := range md.Keys() {
+		parent := strings.Join(key[:len(key)-1], ".")
+		k := key[len(key)-1]
+		if !utils.StringArrayContains(order[parent], k) {
+			order[parent] = append(order[parent], k)
+		}
+	}
+	return orderedMapSlice(m, "", order), nil
+}
+
+// orderedMapSlice returns m as a MapSlice, with the keys in order[path],
+// and then any others in sorted order.
+func orderedMapSlice(m map[string]interface{}, path string, order map[string][]string) yaml.MapSlice {
+	keys := []string{}
+	for _, k := range order[path] {
+		if _, ok := m[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	var rest []string
+	for k := range m {
+		if !utils.StringArrayContains(keys, k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	ms := make(yaml.MapSlice, 0, len(m))
+	for _, k := range append(keys, rest...) {
+		childPath := k
+		if path != "" {
+			childPath = path + "." + k
+		}
+		ms = append(ms, yaml.MapItem{Key: k, Value: orderedValue(m[k], childPath, order)})
+	}
+	return ms
+}
+
+func orderedValue(v interface{}, path string, order map[string][]string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return orderedMapSlice(v, path, order)
+	case []map[string]interface{}:
+		// an array of tables
+		items := make([]interface{}, len(v))
+		for i, m := range v {
+			items[i] = orderedMapSlice(m, path, order)
+		}
+		return items
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = orderedValue(item, path, order)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// jsonMapSlice decodes a JSON object into a MapSlice, with its keys in
+// order.
+func jsonMapSlice(b []byte) (yaml.MapSlice, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	v, err := decodeJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+	ms, ok := v.(yaml.MapSlice)
+	if !ok {
+		return nil, fmt.Errorf("the configuration is not a JSON object")
+	}
+	if _, err := d.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the JSON object")
+	}
+	return ms, nil
+}
+
+func decodeJSONValue(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := t.(type) {
+	case json.Delim:
+		if t == '[' {
+			items := []interface{}{}
+			for d.More() {
+				item, err := decodeJSONValue(d)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			_, err := d.Token() // ]
+			return items, err
+		}
+		ms := yaml.MapSlice{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, yaml.MapItem{Key: k, Value: v})
+		}
+		_, err := d.Token() // }
+		return ms, err
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return int(n), nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestConfig_FromDirectory_toml(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.toml": `
+title = "TOML site"
+permalink = "/:title/"
+exclude = ["a", "b"]
+
+[sass]
+style = "compressed"
+sass_dir = "_styles"
+
+[author]
+name = "Me"
+email = "me@example.com"
+`})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.Equal(t, filepath.Join(dir, "_config.toml"), c.ConfigFile)
+	require.Equal(t, "/:title/", c.Permalink)
+	require.Equal(t, []string{"a", "b"}, c.Exclude)
+	require.Equal(t, "_styles", c.Sass.Dir)
+	require.Equal(t, yaml.MapSlice{{Key: "name", Value: "Me"}, {Key: "email", Value: "me@example.com"}}, c.Variables()["author"])
+	var keys []interface{}
+	for _, item := range c.ms {
+		keys = append(keys, item.Key)
+	}
+	require.Equal(t, []interface{}{"title", "permalink", "exclude", "sass", "author"}, keys)
+	require.True(t, c.IsConfigPath("_config.toml"))
+}
+
+func TestConfig_FromDirectory_json(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.json": `{"title": "JSON site", "paginate": 5, "ratio": 1.5, "author": {"name": "Me", "email": "me@example.com"}}`})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	vars := c.Variables()
+	require.Equal(t, "JSON site", vars["title"])
+	require.Equal(t, 5, vars["paginate"])
+	require.Equal(t, 1.5, vars["ratio"])
+	require.Equal(t, yaml.MapSlice{{Key: "name", Value: "Me"}, {Key: "email", Value: "me@example.com"}}, vars["author"])
+}
+
+func TestConfig_loadConfigFiles_formats(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_config.yml":        "title: yaml\nbaseurl: /yaml\nz: 1\n",
+		"_config.dev.toml":   "title = \"toml\"\na = 2\n",
+		"_config.local.json": `{"baseurl": "/json"}`,
+	})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", "_config.yml,_config.dev.toml,_config.local.json"))
+	require.Equal(t, "toml", c.Variables()["title"])
+	require.Equal(t, "/json", c.BaseURL)
+	var keys []interface{}
+	for _, item := range c.ms {
+		keys = append(keys, item.Key)
+	}
+	require.Equal(t, []interface{}{"title", "baseurl", "z", "a"}, keys)
+	require.True(t, c.IsConfigPath("_config.dev.toml"))
+	require.True(t, c.IsConfigPath("_config.local.json"))
+	require.False(t, c.IsConfigPath("_data/x.json"))
+
+	// A relative source, and an absolute --config file
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	source, err := filepath.Rel(wd, dir)
+	require.NoError(t, err)
+	c = Default()
+	require.NoError(t, c.FromDirectory(source, "", "", filepath.Join(dir, "_config.dev.toml")))
+	require.True(t, c.IsConfigPath("_config.dev.toml"))
+}
+
+func TestConfig_FromDirectory_errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.toml": "title = \"x\"\nbad = \n"})
+	c := Default()
+	err := c.FromDirectory(dir, "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "_config.toml:2:")
+
+	dir = t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.json": "{\n\"title\": \"x\",\n}"})
+	err = c.FromDirectory(dir, "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "_config.json:2:")
+}
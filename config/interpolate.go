@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// envRefMatcher matches ${VAR} and ${VAR:-default}, and their escaped form
+// $${VAR}.
+var envRefMatcher = regexp.MustCompile(`\$(\$)?\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// SetEnvAccess sets the command-line options that limit the environment
+// variables that the configuration can read. If safe is non-nil, it
+// overrides the configuration's safe setting. In safe mode, only the
+// variables in allow can be read.
+func (c *Config) SetEnvAccess(safe *bool, allow []string) {
+	c.envSafe, c.envAllow = safe, allow
+}
+
+// expandEnv replaces ${VAR} in the values of the merged YAML configuration b
+// by the environment variable VAR, and ${VAR:-default} by VAR, or default if
+// VAR is unset or empty. $${VAR} is the literal text ${VAR}. It is an error
+// to refer to an unset variable without a default.
+//
+// This runs after the configuration files are merged, and before they are
+// unmarshaled, so that an expanded value can set a field of any type.
+func (c *Config) expandEnv(b []byte) ([]byte, error) {
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(b, &ms); err != nil {
+		return nil, err
+	}
+	safe := c.Safe
+	if i := mapSliceIndex(ms, "safe"); i >= 0 {
+		safe, _ = ms[i].Value.(bool)
+	}
+	if c.envSafe != nil {
+		safe = *c.envSafe
+	}
+	changed := false
+	for i, item := range ms {
+		key, _ := item.Key.(string)
+		value, ok, err := expandEnvValue(item.Value, safe, c.envAllow)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", key, err)
+		} else if s, known := configSchema.keys[key]; ok && known {
+			// The schema check skipped the references; check the values.
+			value = s.coerce(value)
+			s.check([]string{key}, value, func(path []string, msg string, warning bool) {
+				if !warning && err == nil {
+					err = fmt.Errorf("%s: %s", strings.Join(path, "."), msg)
+				}
+			})
+		}
+		if err != nil {
+			if file := c.KeySource(key).File; file != "" {
+				return nil, utils.WrapPathError(err, file)
+			}
+			return nil, err
+		}
+		if ok {
+			if c.envRefs == nil {
+				c.envRefs = map[string]interface{}{}
+			}
+			c.envRefs[key] = item.Value
+			ms[i].Value = value
+			changed = true
+		} else {
+			delete(c.envRefs, key)
+		}
+	}
+	if !changed {
+		return b, nil
+	}
+	return yaml.Marshal(ms)
+}
+
+// RedactedVariables returns Variables, with the values that were read from
+// environment variables replaced by the text that refers to them, so that
+// they can be displayed without revealing secrets.
+func (c *Config) RedactedVariables() map[string]interface{} {
+	m := c.Variables()
+	for k, v := range c.envRefs {
+		if _, ok := m[k]; ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// EnvReferences returns the top-level variables whose values were read from
+// environment variables, with the text that refers to them.
+func (c *Config) EnvReferences() map[string]interface{} {
+	refs := make(map[string]interface{}, len(c.envRefs))
+	for k, v := range c.envRefs {
+		refs[k] = v
+	}
+	return refs
+}
+
+// expandEnvValue returns a copy of v with the references in its strings
+// replaced, and whether there were any.
+func expandEnvValue(v interface{}, safe bool, allow []string) (interface{}, bool, error) {
+	switch v := v.(type) {
+	case string:
+		return expandEnvString(v, safe, allow)
+	case yaml.MapSlice:
+		var result yaml.MapSlice
+		for i, item := range v {
+			value, ok, err := expandEnvValue(item.Value, safe, allow)
+			switch {
+			case err != nil:
+				return nil, false, err
+			case ok && result == nil:
+				result = append(yaml.MapSlice{}, v...)
+				fallthrough
+			case ok:
+				result[i].Value = value
+			}
+		}
+		return result, result != nil, nil
+	case []interface{}:
+		var result []interface{}
+		for i, item := range v {
+			value, ok, err := expandEnvValue(item, safe, allow)
+			switch {
+			case err != nil:
+				return nil, false, err
+			case ok && result == nil:
+				result = append([]interface{}{}, v...)
+				fallthrough
+			case ok:
+				result[i] = value
+			}
+		}
+		return result, result != nil, nil
+	default:
+		return v, false, nil
+	}
+}
+
+func expandEnvString(s string, safe bool, allow []string) (string, bool, error) {
+	var (
+		found bool
+		err   error
+	)
+	result := envRefMatcher.ReplaceAllStringFunc(s, func(ref string) string {
+		found = true
+		m := envRefMatcher.FindStringSubmatch(ref)
+		name, hasDefault := m[2], strings.Contains(ref, ":-")
+		switch {
+		case m[1] != "":
+			return ref[1:]
+		case err != nil:
+			return ""
+		case safe && !utils.StringArrayContains(allow, name):
+			err = fmt.Errorf("safe mode doesn't allow the environment variable %s; add it to --env-allow", name)
+			return ""
+		}
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if hasDefault {
+			return m[3]
+		}
+		if _, ok := os.LookupEnv(name); !ok {
+			err = fmt.Errorf("the environment variable %s is not set; use ${%s:-default} to provide a default", name, name)
+		}
+		return ""
+	})
+	return result, found, err
+}
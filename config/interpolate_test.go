@@ -0,0 +1,113 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestConfig_expandEnv(t *testing.T) {
+	t.Setenv("GA_ID", "G-1234")
+	t.Setenv("API_HOST", "api.example.com")
+	t.Setenv("EMPTY", "")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_config.yml": `
+url: https://${API_HOST}
+baseurl: ${BASEURL:-/docs}
+analytics:
+  id: ${GA_ID}
+  hosts: ["${API_HOST}", "cdn.example.com"]
+empty: ${EMPTY}
+literal: $${GA_ID}
+port: 4001
+`,
+	})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.Equal(t, "https://api.example.com", c.AbsoluteURL)
+	require.Equal(t, "/docs", c.BaseURL)
+	require.Equal(t, 4001, c.Port)
+	vars := c.Variables()
+	require.Equal(t, "https://api.example.com", vars["url"])
+	require.Equal(t, "/docs", vars["baseurl"])
+	require.Equal(t, yaml.MapSlice{
+		{Key: "id", Value: "G-1234"},
+		{Key: "hosts", Value: []interface{}{"api.example.com", "cdn.example.com"}},
+	}, vars["analytics"])
+	require.Equal(t, "", vars["empty"])
+	require.Equal(t, "${GA_ID}", vars["literal"])
+	redacted := c.RedactedVariables()
+	require.Equal(t, "https://${API_HOST}", redacted["url"])
+	require.Equal(t, "$${GA_ID}", redacted["literal"])
+	require.Equal(t, 4001, redacted["port"])
+	s, _ := c.String("baseurl")
+	require.Equal(t, "/docs", s)
+	require.Equal(t, ConfigFileLayer, c.KeySource("url").Layer)
+}
+
+func TestConfig_expandEnv_afterMerging(t *testing.T) {
+	t.Setenv("SITE_URL", "https://staging.example.com")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"_config.yml":     "url: https://example.com\n",
+		"_config.dev.yml": "url: ${SITE_URL}\n",
+	})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", "_config.yml,_config.dev.yml"))
+	require.Equal(t, "https://staging.example.com", c.AbsoluteURL)
+}
+
+func TestConfig_expandEnv_errors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.yml": "title: ${GOJEKYLL_TEST_UNSET}\n"})
+	c := Default()
+	err := c.FromDirectory(dir, "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), filepath.Join(dir, "_config.yml"))
+	require.Contains(t, err.Error(), "GOJEKYLL_TEST_UNSET is not set")
+}
+
+func TestConfig_expandEnv_safe(t *testing.T) {
+	t.Setenv("GA_ID", "G-1234")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.yml": "analytics: ${GA_ID}\nhome: ${HOME:-none}\n"})
+
+	safe := true
+	c := Default()
+	c.SetEnvAccess(&safe, []string{"GA_ID"})
+	err := c.FromDirectory(dir, "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "safe mode doesn't allow the environment variable HOME")
+
+	c = Default()
+	c.SetEnvAccess(&safe, []string{"GA_ID", "HOME"})
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.Equal(t, "G-1234", c.Variables()["analytics"])
+
+	// The configuration can turn on safe mode too.
+	writeFiles(t, dir, map[string]string{"_config.yml": "safe: true\nanalytics: ${GA_ID}\n"})
+	c = Default()
+	err = c.FromDirectory(dir, "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GA_ID")
+}
+
+func TestConfig_expandEnv_types(t *testing.T) {
+	t.Setenv("PORT", "8080")
+	t.Setenv("SHOW_DRAFTS", "true")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.yml": "port: ${PORT}\nshow_drafts: ${SHOW_DRAFTS}\ntitle: ${PORT}\n"})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.Equal(t, 8080, c.Port)
+	require.True(t, c.Drafts)
+	require.Equal(t, "8080", c.Variables()["title"])
+
+	t.Setenv("PORT", "80x")
+	c = Default()
+	err := c.FromDirectory(dir, "", "", "")
+	require.EqualError(t, err, filepath.Join(dir, "_config.yml")+`: port: expected an integer, not the string "80x"`)
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsLocalized returns true if the site is built in more than one language,
+// in the style of jekyll-polyglot.
+func (c *Config) IsLocalized() bool {
+	return len(c.Languages) > 1
+}
+
+// DefaultLanguage returns default_lang; else the first of the languages;
+// else "en".
+func (c *Config) DefaultLanguage() string {
+	switch {
+	case c.DefaultLang != "":
+		return c.DefaultLang
+	case len(c.Languages) > 0:
+		return c.Languages[0]
+	default:
+		return "en"
+	}
+}
+
+// ActiveLanguage returns the language that the site is being built in.
+func (c *Config) ActiveLanguage() string {
+	if c.ActiveLang != "" {
+		return c.ActiveLang
+	}
+	return c.DefaultLanguage()
+}
+
+// IsExcludedFromLocalization returns true if the site-relative path rel is
+// in exclude_from_localization. The language builds don't copy these files;
+// and links to them aren't rewritten to the language's URL prefix.
+func (c *Config) IsExcludedFromLocalization(rel string) bool {
+	rel = strings.TrimPrefix(filepath.ToSlash(rel), "/")
+	for _, p := range c.ExcludeFromLocalization {
+		p = strings.Trim(filepath.ToSlash(p), "/")
+		if rel == p || strings.HasPrefix(rel, p+"/") {
+			return true
+		}
+	}
+	return false
+}
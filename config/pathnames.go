@@ -46,3 +46,13 @@ func (c *Config) OutputExt(pathname string) string {
 		return filepath.Ext(pathname)
 	}
 }
+
+// SchemasDir is the directory of the front matter schema files, relative to
+// the site source.
+const SchemasDir = "_schemas"
+
+// SchemaFile returns the path of the front matter schema file of the
+// collection name. The schema of the pages outside collections is "pages".
+func (c *Config) SchemaFile(name string) string {
+	return filepath.Join(c.Source, SchemasDir, name+".yml")
+}
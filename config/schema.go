@@ -0,0 +1,445 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A valueType is a set of YAML value types.
+type valueType int
+
+const (
+	stringType valueType = 1 << iota // any scalar, since YAML converts these to strings
+	boolType
+	intType
+	floatType
+	listType
+	mapType
+	anyType = stringType | boolType | intType | floatType | listType | mapType
+)
+
+// A schema describes a configuration value. If keys is non-nil, a map may
+// only have those keys; else it may have any.
+type schema struct {
+	types valueType
+	keys  map[string]schema
+	items *schema // the schema of the items of a list
+}
+
+// configSchema describes the top-level configuration variables. A site can
+// set others; the checker warns only about those that look like typos.
+var configSchema = buildConfigSchema()
+
+// buildConfigSchema returns the schema of the Config fields, and the other
+// variables that Jekyll and the plugins that gojekyll emulates read.
+func buildConfigSchema() schema {
+	s := structSchema(reflect.TypeOf(Config{}))
+	for key, v := range extraConfigSchema {
+		s.keys[key] = v
+	}
+	return s
+}
+
+func structSchema(t reflect.Type) schema {
+	s := schema{types: mapType, keys: map[string]schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if key, ok := fieldYAMLKey(field); ok {
+			s.keys[key] = typeSchema(field.Type)
+		}
+	}
+	return s
+}
+
+func typeSchema(t reflect.Type) schema {
+	switch t.Kind() {
+	case reflect.String:
+		return schema{types: stringType}
+	case reflect.Bool:
+		return schema{types: boolType}
+	case reflect.Int, reflect.Int64:
+		return schema{types: intType}
+	case reflect.Float64:
+		return schema{types: intType | floatType}
+	case reflect.Slice:
+		items := typeSchema(t.Elem())
+		return schema{types: listType, items: &items}
+	case reflect.Map:
+		return schema{types: mapType}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return schema{types: anyType}
+	}
+}
+
+func closedMap(keys map[string]schema) schema {
+	return schema{types: mapType, keys: keys}
+}
+
+var (
+	stringSchema = schema{types: stringType}
+	boolSchema   = schema{types: boolType}
+	intSchema    = schema{types: intType}
+	mapSchema    = schema{types: mapType}
+	anySchema    = schema{types: anyType}
+	listSchema   = schema{types: listType}
+)
+
+// extraConfigSchema describes variables that aren't Config fields, and
+// replaces the schema of some that are.
+var extraConfigSchema = map[string]schema{
+	// Jekyll
+	"collections":         {types: listType | mapType},
+	"collections_dir":     stringSchema,
+	"detach":              boolSchema,
+	"encoding":            stringSchema,
+	"gems":                listSchema,
+	"highlighter":         stringSchema,
+	"kramdown":            mapSchema,
+	"limit_posts":         intSchema,
+	"liquid":              mapSchema,
+	"markdown":            stringSchema,
+	"paginate":            intSchema,
+	"paginate_path":       stringSchema,
+	"plugins_dir":         {types: stringType | listType},
+	"quiet":               boolSchema,
+	"show_dir_listing":    boolSchema,
+	"strict_config":       boolSchema,
+	"strict_front_matter": boolSchema,
+	"taxonomies":          {types: listType | mapType},
+	"webrick":             mapSchema,
+	"whitelist":           listSchema,
+	"csv_reader":          closedMap(map[string]schema{"converters": anySchema, "headers": boolSchema, "encoding": stringSchema}),
+	"tsv_reader":          closedMap(map[string]schema{"converters": anySchema, "headers": boolSchema, "encoding": stringSchema}),
+	"sass": closedMap(map[string]schema{
+		"sass_dir":             stringSchema,
+		"style":                stringSchema,
+		"load_paths":           listSchema,
+		"sourcemap":            stringSchema,
+		"quiet_deps":           boolSchema,
+		"implementation":       stringSchema,
+		"silence_deprecations": listSchema,
+	}),
+
+	// Site metadata, which themes and plugins read
+	"title":       stringSchema,
+	"description": stringSchema,
+	"author":      anySchema,
+	"email":       stringSchema,
+	"lang":        stringSchema,
+	"repository":  stringSchema,
+
+	// Plugins
+	"remote_theme":        stringSchema,
+	"remote_theme_mirror": stringSchema,
+	"last-modified-at":    closedMap(map[string]schema{"date-format": stringSchema}),
+	"feed": closedMap(map[string]schema{
+		"path":                   stringSchema,
+		"posts_limit":            intSchema,
+		"excerpt_only":           boolSchema,
+		"categories":             anySchema,
+		"collections":            anySchema,
+		"tags":                   anySchema,
+		"disable_in_development": boolSchema,
+		"xsl":                    boolSchema,
+	}),
+	"jekyll-archives": closedMap(map[string]schema{
+		"enabled":    {types: stringType | listType},
+		"layout":     stringSchema,
+		"layouts":    mapSchema,
+		"permalinks": mapSchema,
+		"slug_mode":  stringSchema,
+	}),
+	"pagination": closedMap(map[string]schema{
+		"enabled":      boolSchema,
+		"debug":        boolSchema,
+		"collection":   stringSchema,
+		"per_page":     intSchema,
+		"permalink":    stringSchema,
+		"title":        stringSchema,
+		"limit":        intSchema,
+		"offset":       intSchema,
+		"sort_field":   stringSchema,
+		"sort_reverse": boolSchema,
+		"category":     stringSchema,
+		"tag":          stringSchema,
+		"locale":       stringSchema,
+		"indexpage":    stringSchema,
+		"extension":    stringSchema,
+		"trail":        closedMap(map[string]schema{"before": intSchema, "after": intSchema}),
+	}),
+	"autopages": mapSchema,
+	"gist":      closedMap(map[string]schema{"noscript": boolSchema}),
+}
+
+// A misspellingWarning is a warning about a top-level variable that is close
+// to a known one. It may be the site's own variable, such as authors, so
+// strict_config doesn't make it an error.
+type misspellingWarning struct{ error }
+
+// checkConfigFile checks the variables that a configuration file sets
+// against the schema. It returns an error for a value of the wrong type, and
+// adds a warning for an unknown variable. prefix is the path of the section
+// that holds the variables, in a file that has sections.
+func (c *Config) checkConfigFile(ms yaml.MapSlice, filename string, source []byte, prefix ...string) error {
+	var errs []error
+	wrap := func(path []string, msg string) error {
+		return utils.WrapPathLineError(
+			fmt.Errorf("%s: %s", strings.Join(path, "."), msg),
+			filename, keyLine(source, append(append([]string{}, prefix...), path...)))
+	}
+	report := func(path []string, msg string, warning bool) {
+		if warning {
+			c.warnings = append(c.warnings, wrap(path, msg))
+		} else {
+			errs = append(errs, wrap(path, msg))
+		}
+	}
+	for _, item := range ms {
+		key, ok := item.Key.(string)
+		if !ok {
+			continue
+		}
+		if s, ok := configSchema.keys[key]; ok {
+			s.check([]string{key}, item.Value, report)
+		} else if suggestion := suggestKey(key, configSchema.keys); suggestion != "" {
+			// A site can set its own variables, so only warn about a likely
+			// misspelling.
+			err := wrap([]string{key}, fmt.Sprintf("unknown configuration variable; did you mean %s?", suggestion))
+			c.warnings = append(c.warnings, misspellingWarning{err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check checks value, at path, against the schema.
+func (s schema) check(path []string, value interface{}, report func([]string, string, bool)) {
+	if s, ok := value.(string); value == nil || ok && envRefMatcher.MatchString(s) {
+		// expandEnv checks the value once it's expanded.
+		return
+	}
+	t := typeOf(value)
+	if s.types&t == 0 {
+		report(path, fmt.Sprintf("expected %s, not %s", s.types, describeValue(value)), false)
+		return
+	}
+	switch value := value.(type) {
+	case yaml.MapSlice:
+		if s.keys == nil {
+			return
+		}
+		for _, item := range value {
+			key := fmt.Sprint(item.Key)
+			itemPath := append(append([]string{}, path...), key)
+			if ks, ok := s.keys[key]; ok {
+				ks.check(itemPath, item.Value, report)
+				continue
+			}
+			msg := "unknown variable"
+			if suggestion := suggestKey(key, s.keys); suggestion != "" {
+				msg += "; did you mean " + suggestion + "?"
+			}
+			report(itemPath, msg, true)
+		}
+	case []interface{}:
+		if s.items != nil {
+			for _, item := range value {
+				s.items.check(path, item, report)
+			}
+		}
+	}
+}
+
+// coerce returns value, with the strings where the schema doesn't allow a
+// string replaced by the value that YAML reads from them. expandEnv uses this
+// so that, for example, port can be ${PORT}.
+func (s schema) coerce(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		var v interface{}
+		if s.types&stringType == 0 && yaml.Unmarshal([]byte(value), &v) == nil && s.types&typeOf(v) != 0 {
+			return v
+		}
+	case yaml.MapSlice:
+		if s.keys != nil {
+			ms := append(yaml.MapSlice{}, value...)
+			for i, item := range ms {
+				if ks, ok := s.keys[fmt.Sprint(item.Key)]; ok {
+					ms[i].Value = ks.coerce(item.Value)
+				}
+			}
+			return ms
+		}
+	case []interface{}:
+		if s.items != nil {
+			items := make([]interface{}, len(value))
+			for i, item := range value {
+				items[i] = s.items.coerce(item)
+			}
+			return items
+		}
+	}
+	return value
+}
+
+func typeOf(value interface{}) valueType {
+	switch value.(type) {
+	case string:
+		return stringType
+	case bool:
+		return boolType | stringType
+	case int, int64, uint64:
+		return intType | floatType | stringType
+	case float64:
+		return floatType | stringType
+	case []interface{}:
+		return listType
+	case yaml.MapSlice, map[interface{}]interface{}:
+		return mapType
+	default:
+		return anyType
+	}
+}
+
+var valueTypeNames = []struct {
+	t    valueType
+	name string
+}{
+	{stringType, "a string"},
+	{boolType, "a boolean"},
+	{intType, "an integer"},
+	{floatType, "a number"},
+	{listType, "a list"},
+	{mapType, "a map"},
+}
+
+func (t valueType) String() string {
+	var names []string
+	for _, n := range valueTypeNames {
+		if t&n.t != 0 && !(n.t == floatType && t&intType != 0) {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func describeValue(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return fmt.Sprintf("the string %q", value)
+	case []interface{}:
+		return "a list"
+	case yaml.MapSlice, map[interface{}]interface{}:
+		return "a map"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// suggestKey returns the key in keys that key is most likely a misspelling
+// of, or "" if none is close.
+func suggestKey(key string, keys map[string]schema) string {
+	var (
+		limit      = 1 // the most edits to allow
+		best       = limit + 1
+		candidates []string
+	)
+	if len(key) > 5 {
+		limit = 2
+	}
+	for k := range keys {
+		switch d := editDistance(strings.ToLower(key), k); {
+		case d < best:
+			best, candidates = d, []string{k}
+		case d == best && d <= limit:
+			candidates = append(candidates, k)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.Strings(candidates)
+	return candidates[0]
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// keyLine returns the line of a variable in a YAML, TOML or JSON
+// configuration file, or 0 if it can't find it. path is the key path of the
+// variable. This searches for each key after the previous one, which is
+// good enough for configuration files.
+func keyLine(source []byte, path []string) int {
+	offset := 0
+	for _, key := range path {
+		pattern := `(?:^|[\s{,\[.])["']?` + regexp.QuoteMeta(key) + `["']?[ \t]*[:=\]]`
+		loc := regexp.MustCompile(`(?m)` + pattern).FindIndex(source[offset:])
+		if loc == nil {
+			return 0
+		}
+		offset += loc[1]
+	}
+	return 1 + bytes.Count(source[:offset], []byte("\n"))
+}
+
+// checkWarnings reports the warnings from checking the configuration files.
+// With strict_config, those other than misspellingWarnings are errors.
+func (c *Config) checkWarnings() error {
+	strict, _ := c.m["strict_config"].(bool)
+	var errs []error
+	for _, err := range c.warnings {
+		if _, ok := err.(misspellingWarning); strict && !ok {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err)
+	}
+	return errors.Join(errs...)
+}
+
+// checkAdminFile checks the base section of an _admin.yml file, and the
+// section for environment.
+func (c *Config) checkAdminFile(source []byte, filename, environment string) error {
+	var admin struct {
+		Site yaml.MapSlice `yaml:"site"`
+	}
+	if err := yaml.Unmarshal(source, &admin); err != nil {
+		return utils.WrapParseError(err, filename, source)
+	}
+	var errs []error
+	for _, item := range admin.Site {
+		ms, ok := item.Value.(yaml.MapSlice)
+		if ok && (item.Key == "base" || environment != "" && item.Key == environment) {
+			errs = append(errs, c.checkConfigFile(ms, filename, source, "site", fmt.Sprint(item.Key)))
+		}
+	}
+	return errors.Join(errs...)
+}
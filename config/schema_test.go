@@ -0,0 +1,93 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_FromDirectory_schemaWarnings(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.yml": `title: Site
+permlink: /:title/
+colections:
+  recipes:
+    output: true
+my_setting: 1
+feed:
+  pathh: atom.xml
+`})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	filename := filepath.Join(dir, "_config.yml")
+	require.Len(t, c.warnings, 3)
+	require.EqualError(t, c.warnings[0], filename+":2: permlink: unknown configuration variable; did you mean permalink?")
+	require.EqualError(t, c.warnings[1], filename+":3: colections: unknown configuration variable; did you mean collections?")
+	require.EqualError(t, c.warnings[2], filename+":8: feed.pathh: unknown variable; did you mean path?")
+
+	writeFiles(t, dir, map[string]string{"_config.yml": `sass:
+  implementation: sass-embedded
+  silence_deprecations: [import]
+pagination:
+  offset: 2
+`})
+	c = Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.Empty(t, c.warnings)
+
+	// strict_config doesn't apply to top-level variables, which may be the
+	// site's own.
+	writeFiles(t, dir, map[string]string{"_config.yml": "strict_config: true\nauthors: [a]\nfeed:\n  pathh: atom.xml\n"})
+	c = Default()
+	err := c.FromDirectory(dir, "", "", "")
+	require.EqualError(t, err, filename+":4: feed.pathh: unknown variable; did you mean path?")
+	require.Len(t, c.warnings, 2)
+	require.EqualError(t, c.warnings[0], filename+":2: authors: unknown configuration variable; did you mean author?")
+}
+
+func TestConfig_FromDirectory_schemaErrors(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "_config.yml")
+	writeFiles(t, dir, map[string]string{"_config.yml": "title: 2024\nport: \"4000x\"\n"})
+	c := Default()
+	err := c.FromDirectory(dir, "", "", "")
+	require.EqualError(t, err, filename+`:2: port: expected an integer, not the string "4000x"`)
+
+	writeFiles(t, dir, map[string]string{"_config.yml": "exclude: [a, b]\npagination:\n  enabled: true\n  per_page: ten\nsafe: [x]\n"})
+	err = c.FromDirectory(dir, "", "", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), filename+`:4: pagination.per_page: expected an integer, not the string "ten"`)
+	require.Contains(t, err.Error(), filename+`:5: safe: expected a boolean, not a list`)
+
+	dir = t.TempDir()
+	writeFiles(t, dir, map[string]string{"_config.toml": "title = \"Site\"\n\n[sass]\nsass_dir = [\"a\"]\n"})
+	c = Default()
+	err = c.FromDirectory(dir, "", "", "")
+	require.EqualError(t, err, filepath.Join(dir, "_config.toml")+`:4: sass.sass_dir: expected a string, not a list`)
+}
+
+func TestConfig_FromDirectory_schemaAdmin(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"_admin.yml": `site:
+  base:
+    title: Site
+    port: 4000
+  prod:
+    port: eighty
+`})
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	err := c.FromDirectory(dir, "prod", "", "")
+	require.EqualError(t, err, filepath.Join(dir, "_admin.yml")+`:6: port: expected an integer, not the string "eighty"`)
+}
+
+func TestSuggestKey(t *testing.T) {
+	keys := configSchema.keys
+	require.Equal(t, "permalink", suggestKey("permlink", keys))
+	require.Equal(t, "exclude", suggestKey("exlcude", keys))
+	require.Equal(t, "baseurl", suggestKey("base_url", keys))
+	require.Equal(t, "port", suggestKey("Port", keys))
+	require.Equal(t, "", suggestKey("gist_id", keys))
+	require.Equal(t, "", suggestKey("home", keys))
+}
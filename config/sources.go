@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Source tells where a configuration variable was set: the layer of the
+// configuration, and the file if the layer has one.
+type Source struct {
+	Layer string
+	File  string
+}
+
+// The configuration layers, from lowest to highest precedence. The site
+// configuration comes from one of _config.yml, the --config files, the
+// JEKYLL_CONFIG files, or _admin.yml; an _admin.yml environment overrides
+// its base.
+const (
+	DefaultLayer     = "default"
+	ThemeLayer       = "theme"
+	ConfigFileLayer  = "config file"
+	ConfigFlagLayer  = "--config"
+	ConfigEnvLayer   = "JEKYLL_CONFIG"
+	AdminBaseLayer   = "admin site.base"
+	URLEnvLayer      = "JEKYLL_URL"
+	CommandLineLayer = "command line"
+)
+
+func (s Source) String() string {
+	if s.File == "" {
+		return s.Layer
+	}
+	return s.File + " (" + s.Layer + ")"
+}
+
+// KeySource returns where the top-level configuration variable key came
+// from. Its Layer is DefaultLayer if the variable has its default value.
+func (c *Config) KeySource(key string) Source {
+	if src, ok := c.sources[key]; ok {
+		return src
+	}
+	return Source{Layer: DefaultLayer}
+}
+
+// setSources records src as the source of the top-level variables in the
+// YAML text b.
+func (c *Config) setSources(b []byte, src Source) {
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(b, &ms); err != nil {
+		return
+	}
+	for _, item := range ms {
+		if key, ok := item.Key.(string); ok {
+			c.setSource(key, src)
+		}
+	}
+}
+
+func (c *Config) setSource(key string, src Source) {
+	if c.sources == nil {
+		c.sources = map[string]Source{}
+	}
+	c.sources[key] = src
+}
+
+// setAdminSources records the source of the top-level variables in a section
+// of an admin file.
+func (c *Config) setAdminSources(section map[interface{}]interface{}, layer, filename string) {
+	for k := range section {
+		if key, ok := k.(string); ok {
+			c.setSource(key, Source{Layer: layer, File: filename})
+		}
+	}
+}
+
+// setFlagSource records that a flag set the Config field name.
+func (c *Config) setFlagSource(name string) {
+	if key, ok := fieldKey(name); ok {
+		c.setSource(key, Source{Layer: CommandLineLayer})
+	}
+}
+
+// fieldKey returns the configuration variable of the Config field name.
+func fieldKey(name string) (string, bool) {
+	field, ok := reflect.TypeOf(Config{}).FieldByName(name)
+	if !ok {
+		return "", false
+	}
+	return fieldYAMLKey(field)
+}
+
+// fieldYAMLKey returns the YAML key of a struct field, as yaml.Unmarshal
+// reads it.
+func fieldYAMLKey(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	switch key {
+	case "-":
+		return "", false
+	case "":
+		key = strings.ToLower(field.Name)
+	}
+	return key, true
+}
+
+// EffectiveVariables returns the configuration variables as they are after
+// all the layers are merged: the defaults that the configuration files don't
+// override, the values from the files, and the values that flags set.
+//
+// The config command uses this.
+func (c *Config) EffectiveVariables() yaml.MapSlice {
+	ms := append(yaml.MapSlice{}, Default().ms...)
+	for _, item := range c.ms {
+		if i := mapSliceIndex(ms, item.Key); i >= 0 {
+			ms[i].Value = item.Value
+		} else {
+			ms = append(ms, item)
+		}
+	}
+	// Flags set the Config fields, but not the variables.
+	rv, rt := reflect.ValueOf(c).Elem(), reflect.TypeOf(c).Elem()
+	for i := 0; i < rt.NumField(); i++ {
+		key, ok := fieldKey(rt.Field(i).Name)
+		if !ok || c.KeySource(key).Layer != CommandLineLayer {
+			continue
+		}
+		value := rv.Field(i).Interface()
+		if j := mapSliceIndex(ms, key); j >= 0 {
+			ms[j].Value = value
+		} else {
+			ms = append(ms, yaml.MapItem{Key: key, Value: value})
+		}
+	}
+	return ms
+}
+
+// A theme's configuration file can't set these.
+var themeIgnoredKeys = map[string]bool{
+	"source":      true,
+	"destination": true,
+	"theme":       true,
+}
+
+// MergeThemeConfig merges a theme's _config.yml beneath the site
+// configuration. The theme supplies the variables that the site's
+// configuration files and flags don't set, and the entries that they don't
+// set in maps that they do. It does nothing if the file doesn't exist.
+func (c *Config) MergeThemeConfig(filename string) error {
+	b, err := os.ReadFile(filename)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	var theme yaml.MapSlice
+	if err := yaml.Unmarshal(b, &theme); err != nil {
+		return utils.WrapPathError(err, filename)
+	}
+	var (
+		ms          = append(yaml.MapSlice{}, c.ms...)
+		changed     = yaml.MapSlice{}
+		themeSource = Source{Layer: ThemeLayer, File: filename}
+	)
+	for _, item := range theme {
+		key, ok := item.Key.(string)
+		if !ok || themeIgnoredKeys[key] || c.KeySource(key).Layer == CommandLineLayer {
+			continue
+		}
+		i := mapSliceIndex(ms, key)
+		switch src := c.KeySource(key); {
+		case i < 0:
+			ms = append(ms, item)
+			i = len(ms) - 1
+			c.setSource(key, themeSource)
+		case src.Layer == DefaultLayer || src == themeSource:
+			ms[i].Value = mergeMapSliceValues(ms[i].Value, item.Value)
+			c.setSource(key, themeSource)
+		default:
+			ms[i].Value = mergeMapSliceValues(item.Value, ms[i].Value)
+		}
+		changed = append(changed, ms[i])
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	// Unmarshal only the changed variables, so that fields that were set
+	// after the configuration file was read keep their values.
+	b, err = yaml.Marshal(changed)
+	if err != nil {
+		return err
+	}
+	if err := Unmarshal(b, c); err != nil {
+		return utils.WrapPathError(err, filename)
+	}
+	c.ms = ms
+	return nil
+}
+
+func mapSliceIndex(ms yaml.MapSlice, key interface{}) int {
+	for i, item := range ms {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeMapSliceValues returns override, or if both base and override are
+// maps, their deep merge with the entries in override taking precedence.
+func mergeMapSliceValues(base, override interface{}) interface{} {
+	b, ok1 := base.(yaml.MapSlice)
+	o, ok2 := override.(yaml.MapSlice)
+	if !ok1 || !ok2 {
+		return override
+	}
+	result := append(yaml.MapSlice{}, b...)
+	for _, item := range o {
+		if i := mapSliceIndex(result, item.Key); i >= 0 {
+			result[i].Value = mergeMapSliceValues(result[i].Value, item.Value)
+		} else {
+			result = append(result, item)
+		}
+	}
+	return result
+}
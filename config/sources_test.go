@@ -0,0 +1,84 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_KeySource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "_config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("title: x\n"), 0644))
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	verbose := true
+	c.ApplyFlags(Flags{Verbose: &verbose})
+	require.Equal(t, Source{ConfigFileLayer, path}, c.KeySource("title"))
+	require.Equal(t, Source{Layer: CommandLineLayer}, c.KeySource("verbose"))
+	require.Equal(t, Source{Layer: DefaultLayer}, c.KeySource("permalink"))
+	require.Equal(t, path+" (config file)", c.KeySource("title").String())
+}
+
+func TestConfig_MergeThemeConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "_config.yml")
+	themePath := filepath.Join(dir, "theme.yml")
+	require.NoError(t, os.WriteFile(path, []byte("title: site\nsass:\n  style: compressed\n"), 0644))
+	require.NoError(t, os.WriteFile(themePath, []byte("title: theme\npermalink: /:title/\nsass:\n  sass_dir: _theme_sass\n  style: expanded\nsource: elsewhere\n"), 0644))
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.NoError(t, c.MergeThemeConfig(themePath))
+	require.Equal(t, "site", c.Variables()["title"])
+	require.Equal(t, "/:title/", c.Permalink)
+	require.Equal(t, "_theme_sass", c.Sass.Dir)
+	require.Equal(t, dir, c.Source)
+	require.Equal(t, Source{ConfigFileLayer, path}, c.KeySource("title"))
+	require.Equal(t, Source{ThemeLayer, themePath}, c.KeySource("permalink"))
+
+	require.NoError(t, c.MergeThemeConfig(filepath.Join(dir, "missing.yml")))
+
+	// A flag overrides the theme.
+	require.NoError(t, os.WriteFile(themePath, []byte("port: 5000\n"), 0644))
+	c = Default()
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	port := 4001
+	c.ApplyFlags(Flags{Port: &port})
+	require.NoError(t, c.MergeThemeConfig(themePath))
+	require.Equal(t, 4001, c.Port)
+	require.Equal(t, Source{Layer: CommandLineLayer}, c.KeySource("port"))
+}
+
+func TestConfig_EffectiveVariables(t *testing.T) {
+	dir := t.TempDir()
+	admin := "site:\n  base:\n    title: base\n    baseurl: /base\n  prod:\n    title: prod\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_admin.yml"), []byte(admin), 0644))
+	c := Default()
+	require.NoError(t, c.FromDirectory(dir, "prod", "", ""))
+	dest := "/tmp/out"
+	c.ApplyFlags(Flags{Destination: &dest})
+	vars := c.EffectiveVariables()
+	value := func(key string) interface{} {
+		i := mapSliceIndex(vars, key)
+		require.True(t, i >= 0, key)
+		return vars[i].Value
+	}
+	require.Equal(t, "prod", value("title"))
+	require.Equal(t, "/base", value("baseurl"))
+	require.Equal(t, "/tmp/out", value("destination"))
+	require.Equal(t, "_layouts", value("layouts_dir"))
+	adminPath := filepath.Join(dir, "_admin.yml")
+	require.Equal(t, Source{"admin site.prod", adminPath}, c.KeySource("title"))
+	require.Equal(t, Source{AdminBaseLayer, adminPath}, c.KeySource("baseurl"))
+	require.Equal(t, Source{Layer: CommandLineLayer}, c.KeySource("destination"))
+	require.Equal(t, Source{Layer: DefaultLayer}, c.KeySource("layouts_dir"))
+
+	c = Default()
+	t.Setenv("JEKYLL_CONFIG", "_config.local.yml")
+	localPath := filepath.Join(dir, "_config.local.yml")
+	require.NoError(t, os.WriteFile(localPath, []byte("title: x\n"), 0644))
+	require.NoError(t, c.FromDirectory(dir, "", "", ""))
+	require.Equal(t, Source{ConfigEnvLayer, localPath}, c.KeySource("title"))
+}
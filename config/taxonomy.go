@@ -0,0 +1,63 @@
+package config
+
+import (
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Taxonomy groups documents by the values of a front matter field, as
+// site.tags groups posts by their tags.
+type Taxonomy struct {
+	// Field is the front matter field. It defaults to the taxonomy name.
+	Field string
+	// Collections are the names of the collections whose documents the
+	// taxonomy groups. It defaults to posts.
+	Collections []string
+}
+
+// FieldName returns the front matter field that the taxonomy named name
+// groups by.
+func (t Taxonomy) FieldName(name string) string {
+	if t.Field != "" {
+		return t.Field
+	}
+	return name
+}
+
+// CollectionNames returns the names of the collections whose documents the
+// taxonomy groups.
+func (t Taxonomy) CollectionNames() []string {
+	if len(t.Collections) != 0 {
+		return t.Collections
+	}
+	return []string{"posts"}
+}
+
+type taxonomiesList struct {
+	Taxonomies []string
+}
+
+type taxonomiesMap struct {
+	Taxonomies map[string]Taxonomy
+}
+
+// unmarshalTaxonomies adds the taxonomies in the configuration. These are
+// either a list of names, or a map from names to Taxonomy settings.
+func (c *Config) unmarshalTaxonomies(bytes []byte) {
+	var (
+		tList taxonomiesList
+		tMap  taxonomiesMap
+	)
+	if c.Taxonomies == nil {
+		c.Taxonomies = map[string]Taxonomy{}
+	}
+	if err := yaml.Unmarshal(bytes, &tList); err == nil {
+		for _, name := range tList.Taxonomies {
+			c.Taxonomies[name] = Taxonomy{}
+		}
+	}
+	if err := yaml.Unmarshal(bytes, &tMap); err == nil {
+		for name, t := range tMap.Taxonomies {
+			c.Taxonomies[name] = t
+		}
+	}
+}
@@ -0,0 +1,371 @@
+package frontmatter
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Schema describes the front matter of the pages in a collection. It is a
+// subset of JSON Schema: the keywords are type, enum, required, properties,
+// additionalProperties (true or false), items, minimum, maximum, minLength,
+// maxLength and pattern. The annotations title, description, default and
+// $schema are allowed, and ignored.
+type Schema struct {
+	types                []string
+	enum                 []interface{}
+	required             []string
+	properties           map[string]*Schema
+	additionalProperties bool
+	items                *Schema
+	minimum, maximum     *float64
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+}
+
+var schemaTypes = []string{"string", "integer", "number", "boolean", "array", "object", "null"}
+
+var schemaAnnotations = map[string]bool{"title": true, "description": true, "default": true, "$schema": true}
+
+// ReadSchemaFile reads a schema from a YAML or JSON file. It returns nil if
+// the file doesn't exist.
+func ReadSchemaFile(filename string) (*Schema, error) {
+	b, err := os.ReadFile(filename)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, utils.WrapParseError(err, filename, b)
+	}
+	s, err := NewSchema(v)
+	return s, utils.WrapPathError(err, filename)
+}
+
+// NewSchema creates a schema from its YAML or JSON value.
+func NewSchema(v interface{}) (*Schema, error) {
+	m, ok := utils.ConvertYAMLValue(v).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("a schema must be a map, not %s", describeValue(v))
+	}
+	return parseSchema(m, "")
+}
+
+func parseSchema(m map[string]interface{}, path string) (*Schema, error) {
+	s := Schema{additionalProperties: true}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := m[k]
+		var err error
+		switch k {
+		case "type":
+			err = s.parseType(v)
+		case "enum":
+			var ok bool
+			if s.enum, ok = v.([]interface{}); !ok {
+				err = fmt.Errorf("must be a list")
+			}
+		case "required":
+			s.required, err = stringList(v)
+		case "properties":
+			props, ok := v.(map[string]interface{})
+			if !ok {
+				err = fmt.Errorf("must be a map")
+				break
+			}
+			s.properties = map[string]*Schema{}
+			for name, pv := range props {
+				pm, ok := pv.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("schema %s: must be a map", joinPath(path, name))
+				}
+				if s.properties[name], err = parseSchema(pm, joinPath(path, name)); err != nil {
+					return nil, err
+				}
+			}
+		case "additionalProperties":
+			var ok bool
+			if s.additionalProperties, ok = v.(bool); !ok {
+				err = fmt.Errorf("must be true or false")
+			}
+		case "items":
+			im, ok := v.(map[string]interface{})
+			if !ok {
+				err = fmt.Errorf("must be a map")
+				break
+			}
+			if s.items, err = parseSchema(im, joinPath(path, "items")); err != nil {
+				return nil, err
+			}
+		case "minimum", "maximum":
+			n, ok := toFloat(v)
+			if !ok {
+				err = fmt.Errorf("must be a number")
+			} else if k == "minimum" {
+				s.minimum = &n
+			} else {
+				s.maximum = &n
+			}
+		case "minLength", "maxLength":
+			n, ok := v.(int)
+			if !ok || n < 0 {
+				err = fmt.Errorf("must be a non-negative integer")
+			} else if k == "minLength" {
+				s.minLength = &n
+			} else {
+				s.maxLength = &n
+			}
+		case "pattern":
+			str, ok := v.(string)
+			if !ok {
+				err = fmt.Errorf("must be a string")
+			} else {
+				s.pattern, err = regexp.Compile(str)
+			}
+		default:
+			if !schemaAnnotations[k] {
+				err = fmt.Errorf("unsupported keyword")
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", joinPath(path, k), err)
+		}
+	}
+	return &s, nil
+}
+
+func (s *Schema) parseType(v interface{}) error {
+	types, err := stringList(v)
+	if err != nil {
+		return err
+	}
+	for _, t := range types {
+		if !utils.StringArrayContains(schemaTypes, t) {
+			return fmt.Errorf("%q is not one of %s", t, strings.Join(schemaTypes, ", "))
+		}
+	}
+	s.types = types
+	return nil
+}
+
+// stringList returns a string or list of strings as a list.
+func stringList(v interface{}) ([]string, error) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		list := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("must be a list of strings")
+			}
+			list[i] = s
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("must be a string or a list of strings")
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// implicitKeys are the front matter variables that gojekyll sets, from the
+// collection and the filename. additionalProperties doesn't apply to them.
+var implicitKeys = map[string]bool{
+	"collection": true,
+	"permalink":  true,
+	"draft":      true,
+	"date":       true,
+	"title":      true,
+	"slug":       true,
+	"categories": true,
+}
+
+// Validate returns the ways in which fm doesn't match the schema, in order of
+// variable name.
+func (s *Schema) Validate(fm FrontMatter) []error {
+	var errs []error
+	s.validate("", map[string]interface{}(fm), func(path, msg string) {
+		if path != "" {
+			msg = path + ": " + msg
+		}
+		errs = append(errs, fmt.Errorf("%s", msg))
+	})
+	return errs
+}
+
+func (s *Schema) validate(path string, v interface{}, report func(path, msg string)) {
+	if len(s.types) > 0 && !s.hasType(v) {
+		report(path, fmt.Sprintf("expected %s, not %s", strings.Join(s.types, " or "), describeValue(v)))
+		return
+	}
+	if len(s.enum) > 0 && !s.inEnum(v) {
+		choices := make([]string, len(s.enum))
+		for i, e := range s.enum {
+			choices[i] = fmt.Sprintf("%v", e)
+		}
+		report(path, fmt.Sprintf("must be one of %s, not %s", strings.Join(choices, ", "), describeValue(v)))
+		return
+	}
+	switch v := v.(type) {
+	case string:
+		s.validateString(path, v, report)
+	case map[string]interface{}:
+		s.validateObject(path, v, report)
+	case map[interface{}]interface{}:
+		s.validateObject(path, utils.ConvertYAMLValue(v).(map[string]interface{}), report)
+	default:
+		if n, ok := toFloat(v); ok {
+			s.validateNumber(path, n, report)
+		} else if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && s.items != nil {
+			for i := 0; i < rv.Len(); i++ {
+				s.items.validate(fmt.Sprintf("%s[%d]", path, i), rv.Index(i).Interface(), report)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateString(path, v string, report func(path, msg string)) {
+	n := len([]rune(v))
+	switch {
+	case s.minLength != nil && n < *s.minLength:
+		report(path, fmt.Sprintf("must be at least %d characters long", *s.minLength))
+	case s.maxLength != nil && n > *s.maxLength:
+		report(path, fmt.Sprintf("must be at most %d characters long", *s.maxLength))
+	case s.pattern != nil && !s.pattern.MatchString(v):
+		report(path, fmt.Sprintf("%q doesn't match the pattern %s", v, s.pattern))
+	}
+}
+
+func (s *Schema) validateNumber(path string, n float64, report func(path, msg string)) {
+	switch {
+	case s.minimum != nil && n < *s.minimum:
+		report(path, fmt.Sprintf("must be at least %v", *s.minimum))
+	case s.maximum != nil && n > *s.maximum:
+		report(path, fmt.Sprintf("must be at most %v", *s.maximum))
+	}
+}
+
+func (s *Schema) validateObject(path string, m map[string]interface{}, report func(path, msg string)) {
+	for _, name := range s.required {
+		if _, ok := m[name]; !ok {
+			report(path, fmt.Sprintf("missing the required variable %s", name))
+		}
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if ps, ok := s.properties[k]; ok {
+			ps.validate(joinPath(path, k), m[k], report)
+		} else if !s.additionalProperties && !(path == "" && implicitKeys[k]) {
+			report(joinPath(path, k), "unknown variable")
+		}
+	}
+}
+
+func (s *Schema) hasType(v interface{}) bool {
+	for _, t := range s.types {
+		if valueHasType(v, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueHasType(v interface{}, t string) bool {
+	switch t {
+	case "string":
+		// A date is a string in JSON.
+		switch v.(type) {
+		case string, time.Time:
+			return true
+		}
+	case "integer":
+		n, ok := toFloat(v)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := toFloat(v)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		return v != nil && reflect.TypeOf(v).Kind() == reflect.Slice
+	case "object":
+		return v != nil && reflect.TypeOf(v).Kind() == reflect.Map
+	case "null":
+		return v == nil
+	}
+	return false
+}
+
+func (s *Schema) inEnum(v interface{}) bool {
+	for _, e := range s.enum {
+		en, ok1 := toFloat(e)
+		vn, ok2 := toFloat(v)
+		if ok1 && ok2 && en == vn || reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func describeValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("the string %q", v)
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprintf("%v", v)
+	case time.Time:
+		return v.Format("2006-01-02")
+	}
+	switch reflect.TypeOf(v).Kind() {
+	case reflect.Slice:
+		return "a list"
+	case reflect.Map:
+		return "a map"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,104 @@
+package frontmatter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const docsSchema = `
+required: [title, section, order]
+additionalProperties: false
+properties:
+  title: {type: string, minLength: 1}
+  section: {type: string, enum: [intro, guide, reference]}
+  order: {type: integer, minimum: 0}
+  tags: {type: array, items: {type: string}}
+  layout: {type: string}
+  updated: {type: [string, "null"]}
+`
+
+func newTestSchema(t *testing.T, src string) *Schema {
+	var v interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(src), &v))
+	s, err := NewSchema(v)
+	require.NoError(t, err)
+	return s
+}
+
+func validate(s *Schema, fm FrontMatter) []string {
+	var msgs []string
+	for _, err := range s.Validate(fm) {
+		msgs = append(msgs, err.Error())
+	}
+	return msgs
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := newTestSchema(t, docsSchema)
+	require.Empty(t, validate(s, FrontMatter{
+		"title": "Intro", "section": "intro", "order": 1,
+		"tags":       []interface{}{"a", "b"},
+		"updated":    nil,
+		"collection": "docs", "permalink": "/:path/", "draft": false,
+	}))
+	require.Equal(t, []string{
+		"missing the required variable section",
+		"missing the required variable order",
+		"title: must be at least 1 characters long",
+	}, validate(s, FrontMatter{"title": ""}))
+	require.Equal(t, []string{
+		`order: expected integer, not the string "first"`,
+		`section: must be one of intro, guide, reference, not the string "misc"`,
+		`tags[1]: expected string, not 2`,
+		"titel: unknown variable",
+	}, validate(s, FrontMatter{
+		"title": "Intro", "titel": "x", "section": "misc", "order": "first",
+		"tags": []interface{}{"a", 2},
+	}))
+	require.Equal(t, []string{"order: must be at least 0"},
+		validate(s, FrontMatter{"title": "x", "section": "guide", "order": -1}))
+}
+
+func TestSchema_Validate_types(t *testing.T) {
+	s := newTestSchema(t, `
+properties:
+  date: {type: string}
+  weight: {type: number, maximum: 10}
+  count: {type: integer}
+  meta: {type: object, required: [id], properties: {id: {type: string, pattern: "^[a-z]+$"}}}
+`)
+	require.Empty(t, validate(s, FrontMatter{
+		"date":   time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		"weight": 1.5,
+		"count":  3.0,
+		"meta":   map[interface{}]interface{}{"id": "abc"},
+	}))
+	require.Equal(t, []string{
+		"count: expected integer, not 1.5",
+		`meta.id: "ABC" doesn't match the pattern ^[a-z]+$`,
+		"weight: must be at most 10",
+	}, validate(s, FrontMatter{
+		"count":  1.5,
+		"meta":   map[interface{}]interface{}{"id": "ABC"},
+		"weight": 11,
+	}))
+	require.Equal(t, []string{"meta: missing the required variable id"},
+		validate(s, FrontMatter{"meta": map[interface{}]interface{}{}}))
+}
+
+func TestNewSchema_errors(t *testing.T) {
+	parse := func(src string) error {
+		var v interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(src), &v))
+		_, err := NewSchema(v)
+		return err
+	}
+	require.EqualError(t, parse("[a]"), "a schema must be a map, not a list")
+	require.EqualError(t, parse("type: text"), `schema type: "text" is not one of string, integer, number, boolean, array, object, null`)
+	require.EqualError(t, parse("properties: {order: {minimum: low}}"), "schema order.minimum: must be a number")
+	require.EqualError(t, parse("oneOf: []"), "schema oneOf: unsupported keyword")
+	require.NoError(t, parse("description: docs\nproperties: {title: {title: Title}}"))
+}
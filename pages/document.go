@@ -28,3 +28,10 @@ type Site interface {
 	RelativePath(string) string
 	RendererManager() renderers.Renderers
 }
+
+// A PageSite is a Site whose template variable depends on the page, for
+// example because its related posts do. If a page's site implements this,
+// the page's "site" template variable is the value of SiteVariable.
+type PageSite interface {
+	SiteVariable(Page) interface{}
+}
@@ -10,15 +10,16 @@ import (
 
 // ToLiquid is part of the liquid.Drop interface.
 func (d *StaticFile) ToLiquid() interface{} {
-	return liquid.IterationKeyedMap(map[string]interface{}{
+	// The front matter of a static file is its front matter defaults, such
+	// as sitemap: false.
+	return liquid.IterationKeyedMap(d.fm.Merged(FrontMatter{
 		"name":          path.Base(d.relPath),
 		"basename":      utils.TrimExt(path.Base(d.relPath)),
 		"path":          d.URL(),
 		"modified_time": d.modTime,
 		"extname":       d.OutputExt(),
-		// de facto:
-		"collection": nil,
-	})
+		"collection":    d.fm.Get("collection", nil),
+	}))
 }
 
 func (f *file) ToLiquid() interface{} {
@@ -24,10 +24,27 @@ type file struct {
 //
 // filename is the absolute filename. relpath is the path relative to the site or collection directory.
 func NewFile(s Site, filename string, relpath string, fm FrontMatter) (Document, error) {
-	hasFM, err := frontmatter.FileHasFrontMatter(filename)
+	isPage, err := isPageFile(s, filename, relpath)
 	if err != nil {
 		return nil, err
 	}
+	return newFile(s, filename, relpath, fm, isPage)
+}
+
+// NewSiteFile creates a Page or StaticFile for a file outside the
+// collections, with the front matter defaults for its type. Defaults scopes
+// of type "pages" don't apply to static files.
+func NewSiteFile(s Site, filename string, relpath string) (Document, error) {
+	isPage, err := isPageFile(s, filename, relpath)
+	if err != nil {
+		return nil, err
+	}
+	typename := map[bool]string{true: "pages", false: ""}[isPage]
+	fm := s.Config().GetFrontMatterDefaults(typename, relpath)
+	return newFile(s, filename, relpath, fm, isPage)
+}
+
+func newFile(s Site, filename string, relpath string, fm FrontMatter, isPage bool) (Document, error) {
 	info, err := os.Stat(filename)
 	if err != nil {
 		return nil, err
@@ -41,14 +58,27 @@ func NewFile(s Site, filename string, relpath string, fm FrontMatter) (Document,
 		relPath:   relpath,
 		outputExt: s.Config().OutputExt(relpath),
 	}
-	if hasFM || !s.Config().RequiresFrontMatter(relpath) {
+	if isPage {
 		return makePage(filename, fields)
 	}
 	fields.permalink = "/" + relpath
+	if _, ok := fm["collection"]; ok {
+		fields.permalink = fields.collectionStaticPermalink()
+	}
 	p := &StaticFile{fields}
 	return p, nil
 }
 
+// isPageFile returns true if NewFile should create a Page rather than a
+// StaticFile: if the file has front matter, or doesn't require it.
+func isPageFile(s Site, filename string, relpath string) (bool, error) {
+	hasFM, err := frontmatter.FileHasFrontMatter(filename)
+	if err != nil {
+		return false, err
+	}
+	return hasFM || !s.Config().RequiresFrontMatter(relpath), nil
+}
+
 func (f *file) String() string {
 	return fmt.Sprintf("%T{Path=%v, Permalink=%v}", f, f.relPath, f.permalink)
 }
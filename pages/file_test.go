@@ -43,3 +43,27 @@ func (rm renderManagerFake) Render(w io.Writer, src []byte, vars liquid.Bindings
 func (rm renderManagerFake) RenderTemplate(src []byte, vars liquid.Bindings, filename string, lineNo int) ([]byte, error) {
 	return append([]byte("rendered: "), src...), nil
 }
+
+func (rm renderManagerFake) LayoutFiles() (map[string]string, error) { return nil, nil }
+
+func TestNewSiteFile(t *testing.T) {
+	cfg := config.FromString(`defaults:
+  - scope: {path: ""}
+    values: {author: me}
+  - scope: {path: "", type: pages}
+    values: {layout: page}
+`)
+	s := siteFake{t, cfg}
+	d, err := NewSiteFile(s, "testdata/excerpt.md", "excerpt.md")
+	require.NoError(t, err)
+	require.False(t, d.IsStatic())
+	require.Equal(t, "page", d.(Page).FrontMatter()["layout"])
+	require.Equal(t, "me", d.(Page).FrontMatter()["author"])
+
+	d, err = NewSiteFile(s, "testdata/static.html", "static.html")
+	require.NoError(t, err)
+	require.True(t, d.IsStatic())
+	fm := d.(*StaticFile).fm
+	require.Nil(t, fm["layout"])
+	require.Equal(t, "me", fm["author"])
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -124,9 +125,38 @@ func readFrontMatter(f *file) (b []byte, lineNo int, err error) {
 		return
 	}
 	f.fm = f.fm.Merged(fm)
+	// The categories from a post's directory (blog/_posts) add to those from
+	// the front matter or its defaults.
+	if pc := f.pathCategories(); len(pc) > 0 {
+		seen := map[string]bool{}
+		var categories []string
+		for _, c := range append(pc, f.fm.SortedStringArray("categories")...) {
+			if !seen[c] {
+				seen[c] = true
+				categories = append(categories, c)
+			}
+		}
+		f.fm["categories"] = categories
+	}
 	return
 }
 
+// pathCategories returns the categories that a post's directory implies:
+// blog/news/_posts/2017-08-02-post.md -> blog, news.
+func (f *file) pathCategories() []string {
+	if f.dfm["collection"] != "posts" {
+		return nil
+	}
+	rel := utils.MustRel(f.site.Config().SourceDir(), utils.MustAbs(f.filename))
+	dirs := strings.Split(path.Dir(filepath.ToSlash(rel)), "/")
+	for i, dir := range dirs {
+		if dir == "_posts" || dir == "_drafts" {
+			return dirs[:i]
+		}
+	}
+	return nil
+}
+
 func (p *page) FrontMatter() FrontMatter {
 	return p.fm
 }
@@ -152,9 +182,13 @@ func (p *page) TemplateContext() map[string]interface{} {
 	if env == "" {
 		env = "development"
 	}
+	var site interface{} = p.site
+	if ps, ok := p.site.(PageSite); ok {
+		site = ps.SiteVariable(p)
+	}
 	return map[string]interface{}{
 		"page": p,
-		"site": p.site,
+		"site": site,
 		"jekyll": map[string]string{
 			"environment": env,
 			"version":     fmt.Sprintf("%s (gojekyll)", version.Version)},
@@ -3,6 +3,10 @@ package pages
 import (
 	"io"
 	"os"
+	"path"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
 )
 
 // A StaticFile is a static file. (Lint made me say this.)
@@ -22,3 +26,26 @@ func (p *StaticFile) Write(w io.Writer) error {
 	_, err = io.Copy(w, in)
 	return err
 }
+
+// collectionStaticPermalink returns the URL of a static file in a collection.
+// Like Jekyll, this applies the collection's permalink pattern to the file's
+// path without its extension, then adds the extension back.
+func (f *file) collectionStaticPermalink() string {
+	var (
+		relpath = utils.TrimExt(f.relPath)
+		ext     = path.Ext(f.relPath)
+		vars    = map[string]string{
+			"collection": f.fm.String("collection", ""),
+			"path":       "/" + relpath,
+			"name":       path.Base(relpath),
+			"output_ext": "",
+		}
+		pattern = f.fm.String("permalink", "/:collection/:path:output_ext")
+	)
+	// Static files don't have the other variables; Jekyll leaves them blank.
+	s := templateVariableMatcher.ReplaceAllStringFunc(pattern, func(m string) string {
+		return vars[m[1:]]
+	})
+	s = strings.TrimSuffix(utils.URLPathClean("/"+s), "/")
+	return s + ext
+}
@@ -13,13 +13,14 @@ import (
 
 type mockSite struct {
 	cfg   *config.Config
+	pages []Page
 	posts []Page
 }
 
 func (s *mockSite) AddHTMLPage(url string, tpl string, fm pages.FrontMatter)     {}
 func (s *mockSite) Config() *config.Config                                        { return s.cfg }
 func (s *mockSite) TemplateEngine() *liquid.Engine                                { return nil }
-func (s *mockSite) Pages() []Page                                                 { return nil }
+func (s *mockSite) Pages() []Page                                                 { return s.pages }
 func (s *mockSite) Posts() []Page                                                 { return s.posts }
 func (s *mockSite) HasLayout(string) bool                                         { return false }
 
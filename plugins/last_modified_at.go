@@ -0,0 +1,136 @@
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/osteele/liquid"
+	"github.com/osteele/liquid/render"
+	"github.com/osteele/tuesday"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type jekyllLastModifiedAtPlugin struct {
+	plugin
+	site Site
+}
+
+func init() {
+	register("jekyll-last-modified-at", &jekyllLastModifiedAtPlugin{})
+}
+
+const lastModifiedAtField = "last_modified_at"
+
+// defaultLastModifiedAtFormat is the plugin's default date-format.
+const defaultLastModifiedAtFormat = "%d-%b-%y"
+
+func (p *jekyllLastModifiedAtPlugin) AfterInitSite(s Site) error {
+	p.site = s
+	return nil
+}
+
+func (p *jekyllLastModifiedAtPlugin) ConfigureTemplateEngine(e *liquid.Engine) error {
+	e.RegisterTag("last_modified_at", p.lastModifiedAtTag)
+	return nil
+}
+
+// PostReadSite sets each page's last_modified_at to the date of the last
+// commit that changed its source; or, if git doesn't know the file, to its
+// modification time. It leaves a last_modified_at in the front matter alone.
+func (p *jekyllLastModifiedAtPlugin) PostReadSite(s Site) error {
+	dir := s.Config().SourceDir()
+	commitTimes := gitCommitTimes(dir)
+	for _, page := range s.Pages() {
+		fm := page.FrontMatter()
+		if _, ok := fm[lastModifiedAtField]; ok || page.Source() == "" {
+			continue
+		}
+		rel, err := filepath.Rel(dir, page.Source())
+		if err != nil {
+			continue
+		}
+		if t, ok := commitTimes[filepath.ToSlash(rel)]; ok {
+			fm[lastModifiedAtField] = t
+		} else if info, err := os.Stat(page.Source()); err == nil {
+			fm[lastModifiedAtField] = info.ModTime()
+		}
+	}
+	return nil
+}
+
+// gitCommitTimes returns the last commit time of each file in dir that git
+// knows, keyed by its slash-separated path relative to dir. It reads these
+// from a single git log, newest commit first. It returns nil if dir isn't in
+// a git work tree, or git isn't installed.
+func gitCommitTimes(dir string) map[string]time.Time {
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "log",
+		"--format=%x00%ct", "--name-only", "--relative", "--no-renames", "--", ".")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var (
+		times   = map[string]time.Time{}
+		current time.Time
+		scanner = bufio.NewScanner(bytes.NewReader(out))
+	)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			if secs, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				current = time.Unix(secs, 0)
+			}
+		case line == "":
+		default:
+			if _, seen := times[line]; !seen {
+				times[line] = current
+			}
+		}
+	}
+	return times
+}
+
+// lastModifiedAtTag renders page.last_modified_at. The tag argument, if
+// present, is a strftime format; otherwise the format is the date-format in
+// the site's last-modified-at configuration.
+//
+//	{% last_modified_at %Y-%m-%d %}
+func (p *jekyllLastModifiedAtPlugin) lastModifiedAtTag(ctx render.Context) (string, error) {
+	format := strings.TrimSpace(ctx.TagArgs())
+	if format == "" {
+		format = p.dateFormat()
+	}
+	value, err := ctx.EvaluateString("page." + lastModifiedAtField)
+	if err != nil {
+		return "", err
+	}
+	t, ok := value.(time.Time)
+	if !ok {
+		return "", nil
+	}
+	return tuesday.Strftime(format, t)
+}
+
+// dateFormat returns last-modified-at.date-format from the site
+// configuration.
+func (p *jekyllLastModifiedAtPlugin) dateFormat() string {
+	if p.site != nil {
+		if cfg, ok := p.site.Config().Variables()["last-modified-at"].(yaml.MapSlice); ok {
+			for _, item := range cfg {
+				if s, ok := item.Value.(string); ok && item.Key == "date-format" {
+					return s
+				}
+			}
+		}
+	}
+	return defaultLastModifiedAtFormat
+}
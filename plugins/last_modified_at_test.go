@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/pages"
+	"github.com/osteele/liquid"
+	"github.com/stretchr/testify/require"
+)
+
+type sourcePage struct {
+	*mockPage
+	source string
+}
+
+func (p sourcePage) Source() string { return p.source }
+
+func TestLastModifiedAtPlugin(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com", "GIT_COMMITTER_DATE="+date)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name string) string {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(filename, []byte(name+time.Now().String()), 0644))
+		return filename
+	}
+	git("", "init", "-q")
+	a, b := write("a.md"), write("b.md")
+	git("2020-01-01T00:00:00Z", "add", ".")
+	git("2020-01-01T00:00:00Z", "commit", "-q", "-m", "first")
+	write("a.md")
+	git("2021-06-15T12:00:00Z", "commit", "-q", "-am", "second")
+	c := write("c.md") // untracked
+
+	cfg := config.Default()
+	cfg.Source = dir
+	var (
+		pa   = sourcePage{&mockPage{fm: pages.FrontMatter{}}, a}
+		pb   = sourcePage{&mockPage{fm: pages.FrontMatter{}}, b}
+		pc   = sourcePage{&mockPage{fm: pages.FrontMatter{}}, c}
+		date = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		pd   = sourcePage{&mockPage{fm: pages.FrontMatter{"last_modified_at": date}}, b}
+		site = &mockSite{cfg: &cfg, pages: []Page{pa, pb, pc, pd}}
+		p    = &jekyllLastModifiedAtPlugin{}
+	)
+	require.NoError(t, p.AfterInitSite(site))
+	require.NoError(t, p.PostReadSite(site))
+	require.True(t, time.Date(2021, 6, 15, 12, 0, 0, 0, time.UTC).Equal(pa.fm["last_modified_at"].(time.Time)))
+	require.True(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Equal(pb.fm["last_modified_at"].(time.Time)))
+	info, err := os.Stat(c)
+	require.NoError(t, err)
+	require.Equal(t, info.ModTime(), pc.fm["last_modified_at"])
+	require.Equal(t, date, pd.fm["last_modified_at"])
+
+	engine := liquid.NewEngine()
+	require.NoError(t, p.ConfigureTemplateEngine(engine))
+	bindings := liquid.Bindings{"page": map[string]interface{}{"last_modified_at": date}}
+	s, err := engine.ParseAndRenderString(`{% last_modified_at %}|{% last_modified_at %Y-%m-%d %}`, bindings)
+	require.NoError(t, err)
+	require.Equal(t, "01-Jan-19|2019-01-01", s)
+}
@@ -0,0 +1,170 @@
+package plugins
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/utils"
+)
+
+// jekyllRemoteThemePlugin emulates the jekyll-remote-theme plugin. It uses
+// the theme from an offline cache, which `gojekyll theme fetch` fills; or,
+// if the site sets remote_theme_mirror, downloads it from there the first
+// time.
+type jekyllRemoteThemePlugin struct{ plugin }
+
+func init() {
+	register("jekyll-remote-theme", jekyllRemoteThemePlugin{})
+}
+
+// AfterInitSite points the site's theme at the cached copy of the remote
+// theme, so that the site finds it the way it finds a local theme.
+func (p jekyllRemoteThemePlugin) AfterInitSite(s Site) error {
+	cfg := s.Config()
+	spec, _ := cfg.String("remote_theme")
+	if spec == "" {
+		return nil
+	}
+	t, err := parseRemoteTheme(spec)
+	if err != nil {
+		return err
+	}
+	dir, err := t.cacheDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		mirror, _ := cfg.String("remote_theme_mirror")
+		if mirror == "" {
+			return fmt.Errorf("the remote theme %s is not in the cache at %s; run `gojekyll theme fetch` while online, or set remote_theme_mirror", t, dir)
+		}
+		if dir, err = t.fetch(mirror); err != nil {
+			return err
+		}
+	}
+	cfg.Theme = dir
+	return nil
+}
+
+// FetchRemoteTheme downloads the site's remote_theme into the cache, from
+// remote_theme_mirror if the site sets it, else from GitHub. It replaces a
+// copy that is already there, and returns the theme directory.
+//
+// The `theme fetch` command uses this.
+func FetchRemoteTheme(cfg *config.Config) (string, error) {
+	spec, _ := cfg.String("remote_theme")
+	if spec == "" {
+		return "", fmt.Errorf("the site does not set remote_theme")
+	}
+	t, err := parseRemoteTheme(spec)
+	if err != nil {
+		return "", err
+	}
+	mirror, _ := cfg.String("remote_theme_mirror")
+	if mirror == "" {
+		mirror = defaultRemoteThemeMirror
+	}
+	return t.fetch(mirror)
+}
+
+// defaultRemoteThemeMirror serves GitHub repository tarballs as
+// /owner/repo/tar.gz/ref.
+const defaultRemoteThemeMirror = "https://codeload.github.com"
+
+type remoteTheme struct{ owner, repo, ref string }
+
+var remoteThemeMatcher = regexp.MustCompile(`^(?:(?:https?://)?github\.com/)?([\w.-]+)/([\w.-]+?)(?:\.git)?(?:@([\w./-]+))?$`)
+
+// parseRemoteTheme parses a remote_theme setting such as owner/repo,
+// owner/repo@ref, or https://github.com/owner/repo@ref. The ref defaults to
+// HEAD, the repository's default branch.
+func parseRemoteTheme(spec string) (remoteTheme, error) {
+	m := remoteThemeMatcher.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil || !isPathSegments(m[1]) || !isPathSegments(m[2]) || m[3] != "" && !isPathSegments(m[3]) {
+		return remoteTheme{}, fmt.Errorf("remote_theme %q is not of the form owner/repo or owner/repo@ref", spec)
+	}
+	t := remoteTheme{owner: m[1], repo: m[2], ref: m[3]}
+	if t.ref == "" {
+		t.ref = "HEAD"
+	}
+	return t, nil
+}
+
+// isPathSegments returns true if none of the slash-separated segments of s
+// is empty, "." or "..", so that it names a directory below the one that it
+// is joined to.
+func isPathSegments(s string) bool {
+	for _, segment := range strings.Split(s, "/") {
+		switch segment {
+		case "", ".", "..":
+			return false
+		}
+	}
+	return true
+}
+
+func (t remoteTheme) String() string {
+	return t.owner + "/" + t.repo + "@" + t.ref
+}
+
+// cacheDir returns the directory of the cached copy of the theme. It is an
+// error for this to be outside the remote themes cache, since fetch replaces
+// it.
+func (t remoteTheme) cacheDir() (string, error) {
+	root := filepath.Join(cache.Dir(), "remote-themes")
+	dir := filepath.Join(root, t.owner, t.repo, filepath.FromSlash(t.ref))
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the remote theme %s is outside the theme cache %s", t, root)
+	}
+	return dir, nil
+}
+
+// fetch downloads the theme tarball from mirror, and unpacks it into the
+// cache directory.
+func (t remoteTheme) fetch(mirror string) (string, error) {
+	url := fmt.Sprintf("%s/%s/%s/tar.gz/%s", strings.TrimSuffix(mirror, "/"), t.owner, t.repo, t.ref)
+	client := http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("fetching the remote theme %s: %w", t, err)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching the remote theme %s: %s: %s", t, url, resp.Status)
+	}
+	dir, err := t.cacheDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".fetch")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	if err := utils.ExtractTarGz(resp.Body, tmp); err != nil {
+		return "", fmt.Errorf("unpacking the remote theme %s: %w", t, err)
+	}
+	// GitHub tarballs hold a single owner-repo-sha directory.
+	root := tmp
+	if entries, err := os.ReadDir(tmp); err == nil && len(entries) == 1 && entries[0].IsDir() {
+		root = filepath.Join(tmp, entries[0].Name())
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(root, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
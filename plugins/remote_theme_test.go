@@ -0,0 +1,98 @@
+package plugins
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteTheme(t *testing.T) {
+	for spec, expected := range map[string]remoteTheme{
+		"owner/repo":                             {"owner", "repo", "HEAD"},
+		"owner/repo@v1.2":                        {"owner", "repo", "v1.2"},
+		"https://github.com/owner/repo.git@main": {"owner", "repo", "main"},
+		"github.com/owner/my.theme@feature/x":    {"owner", "my.theme", "feature/x"},
+	} {
+		actual, err := parseRemoteTheme(spec)
+		require.NoError(t, err, spec)
+		require.Equal(t, expected, actual, spec)
+	}
+	for _, spec := range []string{"repo", "owner/repo@../x", "a/b/c", "../..", "owner/..", "./repo", "owner/repo@.", "owner/repo@a//b"} {
+		_, err := parseRemoteTheme(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestRemoteTheme_cacheDir(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir()) // the cache directory
+	dir, err := remoteTheme{"owner", "repo", "feature/x"}.cacheDir()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cache.Dir(), "remote-themes", "owner", "repo", "feature", "x"), dir)
+
+	_, err = remoteTheme{"..", "..", "HEAD"}.cacheDir()
+	require.Error(t, err)
+	_, err = remoteTheme{"owner", "..", ".."}.cacheDir()
+	require.Error(t, err)
+}
+
+func TestRemoteThemePlugin(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir()) // the cache directory
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/owner/repo/tar.gz/v1" {
+			http.NotFound(w, r)
+			return
+		}
+		zw := gzip.NewWriter(w)
+		tw := tar.NewWriter(zw)
+		content := "{{ content }}"
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "owner-repo-abc123/_layouts/default.html", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		require.NoError(t, zw.Close())
+	}))
+	defer server.Close()
+	p := jekyllRemoteThemePlugin{}
+
+	cfg := config.Default()
+	cfg.Set("remote_theme", "owner/repo@v1")
+	err := p.AfterInitSite(&mockSite{cfg: &cfg})
+	require.Error(t, err, "cold cache and offline")
+	require.Contains(t, err.Error(), "gojekyll theme fetch")
+	require.Empty(t, requests)
+
+	cfg.Set("remote_theme_mirror", server.URL+"/")
+	require.NoError(t, p.AfterInitSite(&mockSite{cfg: &cfg}))
+	require.FileExists(t, filepath.Join(cfg.Theme, "_layouts", "default.html"))
+	require.Equal(t, []string{"/owner/repo/tar.gz/v1"}, requests)
+
+	// from the cache
+	cfg.Theme = ""
+	require.NoError(t, p.AfterInitSite(&mockSite{cfg: &cfg}))
+	require.FileExists(t, filepath.Join(cfg.Theme, "_layouts", "default.html"))
+	require.Len(t, requests, 1)
+
+	// theme fetch replaces the cached copy
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Theme, "stale.txt"), nil, 0644))
+	dir, err := FetchRemoteTheme(&cfg)
+	require.NoError(t, err)
+	require.Equal(t, cfg.Theme, dir)
+	require.NoFileExists(t, filepath.Join(dir, "stale.txt"))
+	require.Len(t, requests, 2)
+
+	cfg.Set("remote_theme", "owner/missing")
+	_, err = FetchRemoteTheme(&cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "404")
+
+}
@@ -3,6 +3,7 @@ package plugins
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"text/template"
 
 	"github.com/osteele/gojekyll/utils"
@@ -64,6 +65,10 @@ func (p seoTag) TagBody() (string, error) {
 	if siteTitle == nil {
 		siteTitle = site["name"]
 	}
+	alternates, localizedURL := seoAlternates(site, page)
+	if localizedURL != "" {
+		canonicalURL = localizedURL
+	}
 	seoTag := map[string]interface{}{
 		"title?": true,
 		"title":  siteTitle,
@@ -73,6 +78,7 @@ func (p seoTag) TagBody() (string, error) {
 		"page_lang":     "en_US",
 		"page_title":    pageTitle,
 		"site_title":    siteTitle,
+		"alternates":    alternates,
 	}
 	copyFields(seoTag, site, append(seoSiteFields, seoPageOrSiteFields...))
 	copyFields(seoTag, page, seoPageOrSiteFields)
@@ -104,6 +110,42 @@ func (p seoTag) TagBody() (string, error) {
 	return min.String(), nil
 }
 
+// seoAlternates returns the hreflang alternates of a page on a localized
+// site, and the URL of the page in the active language. The default language
+// has no prefix; it is also the x-default alternate.
+func seoAlternates(site, page map[string]interface{}) (alternates []map[string]string, activeURL string) {
+	langs, ok := site["languages"].([]interface{})
+	if !ok || len(langs) < 2 {
+		return nil, ""
+	}
+	var (
+		defaultLang = fmt.Sprint(site["default_lang"])
+		activeLang  = fmt.Sprint(site["active_lang"])
+		base        = fmt.Sprint(site["url"])
+	)
+	if baseurl, ok := site["baseurl"].(string); ok {
+		base += strings.TrimSuffix(baseurl, "/")
+	}
+	prefix := func(lang string) string {
+		if lang == defaultLang {
+			return ""
+		}
+		return "/" + lang
+	}
+	for _, lang := range langs {
+		lang := fmt.Sprint(lang)
+		alternates = append(alternates, map[string]string{
+			"hreflang": lang,
+			"href":     fmt.Sprintf("%s%s%s", base, prefix(lang), page["url"]),
+		})
+	}
+	alternates = append(alternates, map[string]string{
+		"hreflang": "x-default",
+		"href":     fmt.Sprintf("%s%s", base, page["url"]),
+	})
+	return alternates, fmt.Sprintf("%s%s%s", base, prefix(activeLang), page["url"])
+}
+
 func copyFields(to, from map[string]interface{}, fields []string) {
 	for _, name := range fields {
 		if value := from[name]; value != nil {
@@ -165,6 +207,10 @@ const seoTagTemplateSource = `{% if seo_tag.title? %}
   <meta property="og:url" content="{{ seo_tag.canonical_url }}" />
 {% endif %}
 
+{% for alternate in seo_tag.alternates %}
+  <link rel="alternate" hreflang="{{ alternate.hreflang }}" href="{{ alternate.href }}" />
+{% endfor %}
+
 {% if seo_tag.site_title %}
   <meta property="og:site_name" content="{{ seo_tag.site_title }}" />
 {% endif %}
@@ -33,3 +33,30 @@ func TestSEOTag(t *testing.T) {
 	require.NoError(t, err)
 	require.Contains(t, s, `<title>site title | page title</title>`)
 }
+
+func TestSEOTag_hreflang(t *testing.T) {
+	engine := liquid.NewEngine()
+	cfg := config.Default()
+	filters.AddJekyllFilters(engine, &cfg)
+	plugins := []string{"jekyll-seo-tag"}
+	_ = Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	bindings := liquid.Bindings{
+		"site": tags.IterationKeyedMap{
+			"url":          "http://example.com",
+			"baseurl":      "/blog",
+			"languages":    []interface{}{"en", "de"},
+			"default_lang": "en",
+			"active_lang":  "de",
+		},
+		"page": tags.IterationKeyedMap{
+			"url": "/about/",
+		},
+	}
+	s, err := engine.ParseAndRenderString(`{% seo %}`, bindings)
+	require.NoError(t, err)
+	require.Contains(t, s, `hreflang=en href=http://example.com/blog/about/`)
+	require.Contains(t, s, `hreflang=de href=http://example.com/blog/de/about/`)
+	require.Contains(t, s, `hreflang=x-default href=http://example.com/blog/about/`)
+	require.Contains(t, s, `rel=canonical href=http://example.com/blog/de/about/`)
+}
@@ -35,31 +35,9 @@ func (p *Manager) ApplyLayout(name string, content []byte, vars liquid.Bindings)
 // FindLayout returns a template for the named layout.
 func (p *Manager) FindLayout(base string, fmp *map[string]interface{}) (tpl *liquid.Template, err error) {
 	// not cached, but the time here is negligible
-	exts := []string{"", ".html"}
-	for _, ext := range strings.Split(p.cfg.MarkdownExt, `,`) {
-		exts = append(exts, "."+ext)
-	}
-	var (
-		filename string
-		content  []byte
-		found    bool
-	)
-loop:
-	for _, dir := range p.layoutDirs() {
-		for _, ext := range exts {
-			filename = filepath.Join(dir, base+ext)
-			content, err = os.ReadFile(filename)
-			if err == nil {
-				found = true
-				break loop
-			}
-			if !os.IsNotExist(err) {
-				return nil, err
-			}
-		}
-	}
-	if !found {
-		return nil, fmt.Errorf("layout not found: %s (searched in: %s)", base, strings.Join(p.layoutDirs(), ", "))
+	filename, content, err := p.findLayoutFile(base)
+	if err != nil {
+		return nil, err
 	}
 	lineNo := 1
 	fm, err := frontmatter.Read(&content, &lineNo)
@@ -76,11 +54,59 @@ loop:
 	return
 }
 
-// LayoutsDir returns the path to the layouts directory.
+// findLayoutFile returns the filename and content of the named layout.
+func (p *Manager) findLayoutFile(base string) (string, []byte, error) {
+	exts := []string{"", ".html"}
+	for _, ext := range strings.Split(p.cfg.MarkdownExt, `,`) {
+		exts = append(exts, "."+ext)
+	}
+	for _, dir := range p.layoutDirs() {
+		for _, ext := range exts {
+			filename := filepath.Join(dir, base+ext)
+			content, err := os.ReadFile(filename)
+			if err == nil {
+				return filename, content, nil
+			}
+			if !os.IsNotExist(err) {
+				return "", nil, err
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("layout not found: %s (searched in: %s)", base, strings.Join(p.layoutDirs(), ", "))
+}
+
+// LayoutFiles returns the file that each layout comes from, keyed by layout
+// name. A layout in the site overrides one in a theme, and a layout in a
+// theme overrides one in the theme that it inherits from.
+func (p *Manager) LayoutFiles() (map[string]string, error) {
+	files := map[string]string{}
+	for _, dir := range p.layoutDirs() {
+		entries, err := os.ReadDir(dir)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+			if _, seen := files[name]; seen || entry.IsDir() {
+				continue
+			}
+			if filename, _, err := p.findLayoutFile(name); err == nil {
+				files[name] = filename
+			}
+		}
+	}
+	return files, nil
+}
+
+// layoutDirs returns the layout directories, in the order that they're
+// searched: the site's, then the themes'.
 func (p *Manager) layoutDirs() []string {
 	dirs := []string{filepath.Join(p.sourceDir(), p.cfg.LayoutsDir)}
-	if p.ThemeDir != "" {
-		dirs = append(dirs, filepath.Join(p.ThemeDir, "_layouts"))
+	for _, dir := range p.ThemeDirs {
+		dirs = append(dirs, filepath.Join(dir, "_layouts"))
 	}
 	return dirs
 }
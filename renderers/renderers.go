@@ -16,6 +16,7 @@ type Renderers interface {
 	ApplyLayout(string, []byte, liquid.Bindings) ([]byte, error)
 	Render(io.Writer, []byte, liquid.Bindings, string, int) error
 	RenderTemplate([]byte, liquid.Bindings, string, int) ([]byte, error)
+	LayoutFiles() (map[string]string, error)
 }
 
 // Manager applies a rendering transformation to a file.
@@ -30,7 +31,7 @@ type Manager struct {
 // Options configures a rendering manager.
 type Options struct {
 	RelativeFilenameToURL tags.LinkTagHandler
-	ThemeDir              string
+	ThemeDirs             []string // the theme, then the theme it inherits from, and so on
 }
 
 // New makes a rendering manager.
@@ -88,8 +89,8 @@ func (p *Manager) RenderTemplate(src []byte, vars liquid.Bindings, filename stri
 
 func (p *Manager) makeLiquidEngine() *liquid.Engine {
 	dirs := []string{filepath.Join(p.cfg.Source, p.cfg.IncludesDir)}
-	if p.ThemeDir != "" {
-		dirs = append(dirs, filepath.Join(p.ThemeDir, "_includes"))
+	for _, dir := range p.ThemeDirs {
+		dirs = append(dirs, filepath.Join(dir, "_includes"))
 	}
 	engine := liquid.NewEngine()
 	filters.AddJekyllFilters(engine, &p.cfg)
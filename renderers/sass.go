@@ -30,8 +30,10 @@ func (p *Manager) copySASSFileIncludes() error {
 		return err
 	}
 	h := md5.New()
-	if p.ThemeDir != "" {
-		if err := p.copySASSFiles(filepath.Join(p.ThemeDir, sassDirName), p.sassTempDir, h); err != nil {
+	// Copy the ancestor themes' partials first, so that a theme's partials
+	// replace those of its parent, and the site's those of the theme.
+	for i := len(p.ThemeDirs) - 1; i >= 0; i-- {
+		if err := p.copySASSFiles(filepath.Join(p.ThemeDirs[i], sassDirName), p.sassTempDir, h); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/osteele/gojekyll/pages"
+	"github.com/osteele/liquid"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// debugPrefix is the URL path prefix that the server reserves for its
+// introspection pages. serve --no-debug-endpoints turns these off.
+const debugPrefix = "/__gojekyll/"
+
+// buildStatus records the most recent rebuild, for the debug endpoints.
+type buildStatus struct {
+	Time     time.Time `json:"time"`
+	Duration float64   `json:"duration"` // seconds
+	Event    string    `json:"event"`
+	Paths    []string  `json:"paths"`
+	Error    string    `json:"error,omitempty"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+var debugLinks = [][]string{
+	{"Routes", "routes"},
+	{"Site variables", "variables"},
+	{"Configuration", "config"},
+	{"Last rebuild", "build"},
+}
+
+// debugHandler serves the introspection pages. Each page is available as
+// HTML, and as JSON by adding ".json" to its path.
+func (s *Server) debugHandler(rw http.ResponseWriter, r *http.Request) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	var (
+		site       = s.Site
+		name       = strings.TrimPrefix(r.URL.Path, debugPrefix)
+		name0      = strings.TrimSuffix(name, ".json")
+		asJSON     = name != name0
+		title      string
+		data       interface{}
+		routeTable []map[string]string
+	)
+	switch name0 {
+	case "":
+		title = "gojekyll"
+	case "routes":
+		title = "Routes"
+		dynamic := r.URL.Query().Get("dynamic") != ""
+		routes := site.RouteTable(dynamic)
+		data = routes
+		urls := make([]string, 0, len(routes))
+		for u := range routes {
+			urls = append(urls, u)
+		}
+		sort.Strings(urls)
+		for _, u := range urls {
+			routeTable = append(routeTable, map[string]string{"url": u, "source": routes[u]})
+		}
+	case "variables":
+		path := r.URL.Query().Get("path")
+		title = "Variables: " + path
+		if path == "" {
+			title = "Site variables"
+		}
+		v, err := site.PathVariables(path)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusNotFound)
+			return
+		}
+		data = v
+	case "config":
+		title = "Configuration"
+		// The values from environment variables may be secrets.
+		data = site.Config().RedactedVariables()
+	case "build":
+		title = "Last rebuild"
+		data = map[string]interface{}{
+			"rebuild":       s.lastBuild,
+			"render_errors": s.renderErrors,
+		}
+	default:
+		http.NotFound(rw, r)
+		return
+	}
+	b, err := json.MarshalIndent(debugValue(data, 0), "", "  ")
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if asJSON {
+		rw.Header().Set("Content-Type", "application/json")
+		_, err = rw.Write(b)
+	} else {
+		var out string
+		out, err = liquid.NewEngine().ParseAndRenderString(debugTemplate, liquid.Bindings{
+			"title":    title,
+			"links":    debugLinks,
+			"prefix":   debugPrefix,
+			"routes":   routeTable,
+			"json":     string(b),
+			"json_url": name0 + ".json?" + r.URL.RawQuery,
+			"index":    name0 == "",
+		})
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, err = io.WriteString(rw, out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
+	}
+}
+
+// debugValue converts template data to a value that encoding/json can
+// marshal. It summarizes documents below the top level, since documents
+// refer to each other (page.next.previous…).
+func debugValue(v interface{}, depth int) interface{} {
+	if depth > 10 {
+		return fmt.Sprintf("%T", v)
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case pages.Document:
+		if depth > 0 {
+			return map[string]interface{}{"url": v.URL(), "path": v.Source()}
+		}
+		return debugValue(liquid.FromDrop(v), depth+1)
+	case liquid.Drop:
+		return debugValue(liquid.FromDrop(v), depth+1)
+	case yaml.MapSlice:
+		m := make(map[string]interface{}, len(v))
+		for _, item := range v {
+			m[fmt.Sprint(item.Key)] = debugValue(item.Value, depth+1)
+		}
+		return m
+	case []byte:
+		return string(v)
+	case time.Time, string, bool, int, float64:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		m := make(map[string]interface{}, rv.Len())
+		for _, k := range rv.MapKeys() {
+			m[fmt.Sprint(k.Interface())] = debugValue(rv.MapIndex(k).Interface(), depth+1)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		a := make([]interface{}, rv.Len())
+		for i := range a {
+			a[i] = debugValue(rv.Index(i).Interface(), depth+1)
+		}
+		return a
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+	}
+	return v
+}
+
+const debugTemplate = `<html><head>
+	<title>{{ title | escape }} · gojekyll</title>
+	<style type="text/css">
+		body { font-family: Menlo, Consolas, monospace; padding: 2rem; line-height: 1.4; }
+		nav a { margin-right: 1.5em; }
+		td { padding: 2px 2em 2px 0; }
+		pre { background-color: #f6f8fa; padding: 1em; overflow: auto; }
+	</style>
+</head>
+	<body>
+		<nav>{% for link in links %}<a href="{{ prefix }}{{ link[1] }}">{{ link[0] }}</a>{% endfor %}</nav>
+		<h1>{{ title | escape }}</h1>
+		{% if index %}
+		<ul>{% for link in links %}<li><a href="{{ prefix }}{{ link[1] }}">{{ link[0] }}</a> (<a href="{{ prefix }}{{ link[1] }}.json">JSON</a>)</li>{% endfor %}</ul>
+		{% elsif routes %}
+		<table>
+		{% for route in routes %}
+			<tr>
+				<td><a href="{{ route.url | escape }}">{{ route.url | escape }}</a></td>
+				<td>{{ route.source | escape }}</td>
+				<td><a href="{{ prefix }}variables?path={{ route.url | url_encode }}">variables</a></td>
+			</tr>
+		{% endfor %}
+		</table>
+		<p><a href="{{ prefix }}{{ json_url }}">JSON</a></p>
+		{% else %}
+		<pre>{{ json | escape }}</pre>
+		<p><a href="{{ prefix }}{{ json_url }}">JSON</a></p>
+		{% endif %}
+	</body>
+</html>`
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/site"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler(t *testing.T) {
+	t.Setenv("GOJEKYLL_TEST_TOKEN", "s3cret")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_config.yml"), []byte("title: Debug Test\ntoken: ${GOJEKYLL_TEST_TOKEN}\napi:\n  key: ${GOJEKYLL_TEST_TOKEN}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.md"), []byte("---\ntitle: Home\n---\nHello\n"), 0644))
+	s, err := site.FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	srv := Server{Site: s}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rw := httptest.NewRecorder()
+		srv.debugHandler(rw, httptest.NewRequest("GET", path, nil))
+		return rw
+	}
+
+	rw := get(debugPrefix + "routes.json")
+	require.Equal(t, http.StatusOK, rw.Code)
+	var routes map[string]string
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &routes))
+	require.Equal(t, filepath.Join(dir, "index.md"), routes["/index.html"])
+
+	rw = get(debugPrefix + "routes")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), `<a href="/index.html">/index.html</a>`)
+
+	rw = get(debugPrefix + "variables.json?path=/")
+	require.Equal(t, http.StatusOK, rw.Code)
+	var vars map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &vars))
+	require.Equal(t, "Home", vars["title"])
+
+	for _, path := range []string{"", "site", "site.token", "site.api"} {
+		rw = get(debugPrefix + "variables.json?path=" + path)
+		require.Equal(t, http.StatusOK, rw.Code, path)
+		require.NotContains(t, rw.Body.String(), "s3cret", path)
+		require.Contains(t, rw.Body.String(), "${GOJEKYLL_TEST_TOKEN}", path)
+	}
+
+	rw = get(debugPrefix + "variables.json?path=/missing")
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = get(debugPrefix + "config.json")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &vars))
+	require.Equal(t, "Debug Test", vars["title"])
+	require.Equal(t, "${GOJEKYLL_TEST_TOKEN}", vars["token"])
+	require.NotContains(t, rw.Body.String(), "s3cret")
+
+	rw = get(debugPrefix + "build.json")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &vars))
+	require.Nil(t, vars["rebuild"])
+
+	rw = get(debugPrefix + "unknown")
+	require.Equal(t, http.StatusNotFound, rw.Code)
+}
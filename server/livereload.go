@@ -1,30 +1,175 @@
 package server
 
 import (
+	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"regexp"
+	"strconv"
+	"sync"
 
+	"github.com/gorilla/websocket"
 	"github.com/jaschaephraim/lrserver"
 )
 
-// liveReloadScriptTag is inserted into the HTML page.
-var liveReloadScriptTag = []byte(`<script src="http://localhost:35729/livereload.js"></script>`)
+// liveReloadPrefix is the URL path prefix that the server reserves for the
+// Live Reload script and websocket. Site pages at these paths are shadowed.
+const liveReloadPrefix = "/__livereload/"
 
-// startLiveReloader starts the Live Reload server as a go routine, and returns immediately
-func (s *Server) startLiveReloader() error {
-	lr := lrserver.New(lrserver.DefaultName, lrserver.DefaultPort)
+// liveReloadScriptTag returns the tag that is inserted into the HTML page.
+//
+// The URL is relative, so the script is fetched from whatever host and scheme
+// served the page. livereload.js derives its websocket host and ws/wss from
+// the script URL, and its websocket path and port from the path and port
+// parameters; without the latter it connects to port 35729.
+func liveReloadScriptTag(port int) []byte {
+	return []byte(fmt.Sprintf(`<script src="%slivereload.js?path=%slivereload&port=%d"></script>`, liveReloadPrefix, liveReloadPrefix[1:], port))
+}
+
+// requestPort returns the port that the browser sent r to. This differs from
+// the server's port behind a Docker port mapping or a reverse proxy.
+func requestPort(r *http.Request) int {
+	if _, p, err := net.SplitHostPort(r.Host); err == nil {
+		if port, err := strconv.Atoi(p); err == nil {
+			return port
+		}
+	}
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		return 443
+	}
+	return 80
+}
+
+// The LiveReload protocols that the server speaks. See
+// http://livereload.com/api/protocol/
+var liveReloadProtocols = []string{
+	"http://livereload.com/protocols/official-7",
+}
+
+// liveReloader is a Live Reload server that shares the site server's port.
+type liveReloader struct {
+	m        sync.Mutex
+	conns    map[*liveReloadConn]bool
+	upgrader websocket.Upgrader
+	compat   *lrserver.Server // stand-alone server on --livereload-port, if any
+}
+
+type liveReloadConn struct {
+	ws   *websocket.Conn
+	send chan interface{}
+}
+
+// startLiveReloader creates the Live Reload server. If port is non-zero, it
+// also starts a stand-alone Live Reload server on that port, for clients
+// such as browser extensions that connect to a fixed port.
+func (s *Server) startLiveReloader(port int) error {
+	lr := &liveReloader{
+		conns: map[*liveReloadConn]bool{},
+		// The page and the websocket are on the same origin, unless the
+		// server is behind a proxy. Either way, there's nothing to protect.
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	}
+	if port != 0 {
+		compat := lrserver.New(lrserver.DefaultName, uint16(port))
+		compat.SetStatusLog(nil)
+		compat.ErrorLog().SetOutput(outputFilter{os.Stdout})
+		go compat.ListenAndServe() // nolint: errcheck
+		lr.compat = compat
+	}
 	s.lr = lr
-	lr.SetStatusLog(nil)
-	lr.ErrorLog().SetOutput(outputFilter{os.Stdout})
-	go lr.ListenAndServe() // nolint: errcheck
 	return nil
 }
 
-// NewLiveReloadInjector returns a writer that injects the Live Reload JavaScript
-// into its wrapped content.
-func NewLiveReloadInjector(w io.Writer) io.Writer {
-	return TagInjector{w, liveReloadScriptTag}
+// ServeHTTP serves the Live Reload script and websocket.
+func (lr *liveReloader) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case liveReloadPrefix + "livereload.js":
+		rw.Header().Set("Content-Type", "application/javascript")
+		if _, err := io.WriteString(rw, lrserver.JS); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
+		}
+	case liveReloadPrefix + "livereload":
+		ws, err := lr.upgrader.Upgrade(rw, r, nil)
+		if err != nil {
+			// Upgrade has already replied with an HTTP error
+			return
+		}
+		lr.serveConn(ws)
+	default:
+		http.NotFound(rw, r)
+	}
+}
+
+// serveConn greets the client, and relays messages to it until it disconnects.
+func (lr *liveReloader) serveConn(ws *websocket.Conn) {
+	c := &liveReloadConn{ws, make(chan interface{}, 16)}
+	lr.m.Lock()
+	lr.conns[c] = true
+	lr.m.Unlock()
+	defer func() {
+		lr.m.Lock()
+		delete(lr.conns, c)
+		close(c.send)
+		lr.m.Unlock()
+		_ = ws.Close()
+	}()
+	go func() {
+		for msg := range c.send {
+			if err := ws.WriteJSON(msg); err != nil {
+				_ = ws.Close()
+				return
+			}
+		}
+	}()
+	c.send <- map[string]interface{}{
+		"command":    "hello",
+		"protocols":  liveReloadProtocols,
+		"serverName": "gojekyll",
+	}
+	// The client only sends its hello and info messages. Read until it
+	// hangs up.
+	for {
+		if _, _, err := ws.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+// broadcast sends a message to each connected client. It drops the message
+// for a client that isn't keeping up.
+func (lr *liveReloader) broadcast(msg interface{}) {
+	lr.m.Lock()
+	defer lr.m.Unlock()
+	for c := range lr.conns {
+		select {
+		case c.send <- msg:
+		default:
+		}
+	}
+}
+
+// Reload tells the clients that the page at url has changed.
+func (lr *liveReloader) Reload(url string) {
+	lr.broadcast(map[string]interface{}{"command": "reload", "path": url, "liveCSS": true})
+	if lr.compat != nil {
+		lr.compat.Reload(url)
+	}
+}
+
+// Alert tells the clients to display a message.
+func (lr *liveReloader) Alert(msg string) {
+	lr.broadcast(map[string]interface{}{"command": "alert", "message": msg})
+	if lr.compat != nil {
+		lr.compat.Alert(msg)
+	}
+}
+
+// NewLiveReloadInjector returns a writer that injects the Live Reload JavaScript,
+// for the port that r was sent to, into its wrapped content.
+func NewLiveReloadInjector(w io.Writer, r *http.Request) io.Writer {
+	return TagInjector{w, liveReloadScriptTag(requestPort(r))}
 }
 
 // Remove the lines that match the exclusion pattern.
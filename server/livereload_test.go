@@ -0,0 +1,83 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/site"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveReloader(t *testing.T) {
+	s := Server{}
+	require.NoError(t, s.startLiveReloader(0))
+	ts := httptest.NewServer(s.lr)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + liveReloadPrefix + "livereload.js")
+	require.NoError(t, err)
+	defer res.Body.Close() // nolint: errcheck
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "application/javascript", res.Header.Get("Content-Type"))
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NotEmpty(t, b)
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + liveReloadPrefix + "livereload"
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer ws.Close() // nolint: errcheck
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(2*time.Second)))
+
+	var msg map[string]interface{}
+	require.NoError(t, ws.ReadJSON(&msg))
+	require.Equal(t, "hello", msg["command"])
+
+	s.lr.Reload("/index.html")
+	require.NoError(t, ws.ReadJSON(&msg))
+	require.Equal(t, "reload", msg["command"])
+	require.Equal(t, "/index.html", msg["path"])
+}
+
+func TestLiveReloadScriptTag(t *testing.T) {
+	tag := string(liveReloadScriptTag(4000))
+	require.Contains(t, tag, `src="/__livereload/livereload.js?path=__livereload/livereload&port=4000"`)
+	require.NotContains(t, tag, "35729")
+}
+
+func TestRequestPort(t *testing.T) {
+	// The server listens on 4000, behind docker run -p 8080:4000.
+	r := httptest.NewRequest("GET", "http://localhost:4000/", nil)
+	r.Host = "localhost:8080"
+	require.Equal(t, 8080, requestPort(r))
+
+	r.Host = "example.com"
+	require.Equal(t, 80, requestPort(r))
+	r.Header.Set("X-Forwarded-Proto", "https")
+	require.Equal(t, 443, requestPort(r))
+	r = httptest.NewRequest("GET", "https://example.com/", nil)
+	require.Equal(t, 443, requestPort(r))
+}
+
+func TestServer_liveReloadPort(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("---\n---\n<html><body>home</body></html>\n"), 0644))
+	s, err := site.FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	srv := Server{Site: s, lr: &liveReloader{}}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Host = "localhost:8080"
+	rw := httptest.NewRecorder()
+	srv.handler(rw, r)
+	require.Equal(t, 4000, s.Config().Port)
+	require.Contains(t, rw.Body.String(), "&port=8080")
+}
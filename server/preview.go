@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/osteele/gojekyll/site"
+)
+
+// previewParam and previewCookie select the preview site, which includes
+// drafts, future posts and unpublished posts. ?preview=1 turns preview on for
+// the rest of the browser session; ?preview=0 turns it off.
+const (
+	previewParam  = "preview"
+	previewCookie = "gojekyll_preview"
+)
+
+// requestSite returns the site variant that the request asks for, and
+// whether this is the preview site. It remembers the choice in a cookie.
+func (s *Server) requestSite(rw http.ResponseWriter, r *http.Request) (*site.Site, bool, error) {
+	var preview bool
+	switch q := r.URL.Query(); {
+	case q.Has(previewParam):
+		v := q.Get(previewParam)
+		preview = v != "0" && v != "false"
+		cookie := &http.Cookie{Name: previewCookie, Value: "1", Path: "/"}
+		if !preview {
+			cookie.MaxAge = -1
+		}
+		http.SetCookie(rw, cookie)
+	default:
+		c, err := r.Cookie(previewCookie)
+		preview = err == nil && c.Value == "1"
+	}
+	if !preview {
+		return s.Site, false, nil
+	}
+	if s.preview == nil {
+		p, err := s.Site.PreviewSite()
+		if err != nil {
+			return nil, true, err
+		}
+		clearAbsoluteURL(p)
+		s.preview = p
+	}
+	return s.preview, true, nil
+}
+
+// previewBannerTag returns a script tag that displays a banner with the
+// active mode, and a link that toggles it.
+func previewBannerTag(preview bool) []byte {
+	var (
+		background = "#e1e4e8"
+		message    = `Reader view · <a href="?preview=1">Preview drafts</a>`
+	)
+	if preview {
+		background = "#ffd33d"
+		message = `Preview: drafts, future and unpublished posts · <a href="?preview=0">Reader view</a>`
+	}
+	return []byte(fmt.Sprintf(previewBannerTemplate, background, message))
+}
+
+const previewBannerTemplate = `<script>document.addEventListener("DOMContentLoaded", function() {
+	var d = document.createElement("div");
+	d.id = "gojekyll-preview-banner";
+	d.style.cssText = "position:fixed;bottom:0;right:0;z-index:2147483647;padding:4px 8px;font:12px sans-serif;color:#24292e;opacity:0.9;background-color:%s";
+	d.innerHTML = '%s';
+	document.body.appendChild(d);
+});</script>`
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/site"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreview(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_config.yml"), []byte("title: Preview Test\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "_posts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_posts", "2020-01-02-draft.html"), []byte("---\npublished: false\n---\n<html><body>draft</body></html>\n"), 0644))
+	s, err := site.FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	srv := Server{Site: s}
+
+	get := func(path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		rw := httptest.NewRecorder()
+		srv.handler(rw, r)
+		return rw
+	}
+	const draftURL = "/2020/01/02/draft.html"
+
+	rw := get(draftURL)
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = get(draftURL + "?preview=1")
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.Contains(t, rw.Body.String(), "draft")
+	require.Contains(t, rw.Body.String(), `gojekyll-preview-banner`)
+	require.Contains(t, rw.Body.String(), `?preview=0`)
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, previewCookie, cookies[0].Name)
+
+	// the cookie keeps preview on
+	rw = get(draftURL, cookies[0])
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	// ?preview=0 turns it off, and clears the cookie
+	rw = get(draftURL+"?preview=0", cookies[0])
+	require.Equal(t, http.StatusNotFound, rw.Code)
+	cookies = rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.True(t, cookies[0].MaxAge < 0)
+}
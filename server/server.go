@@ -11,7 +11,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/jaschaephraim/lrserver"
 	"github.com/osteele/gojekyll/site"
 	"github.com/osteele/liquid"
 	"github.com/pkg/browser"
@@ -21,36 +20,54 @@ import (
 type Server struct {
 	m    sync.Mutex
 	Site *site.Site
-	lr   *lrserver.Server
+	lr   *liveReloader
+
+	preview *site.Site // includes drafts etc.; loaded on demand
+
+	lastBuild    *buildStatus      // for the debug endpoints
+	renderErrors map[string]string // URL path -> error, since the last rebuild
 }
 
 // Run runs the server.
 func (s *Server) Run(open bool, logger func(label, value string)) error {
 	cfg := s.Site.Config()
-	// Only clear URL if JEKYLL_URL is not set
-	if jekyllURL := os.Getenv("JEKYLL_URL"); jekyllURL != "" {
-		s.Site.SetAbsoluteURL(jekyllURL)
-	} else {
-		s.Site.SetAbsoluteURL("")
+	clearAbsoluteURL(s.Site)
+	certFile, keyFile, err := tlsFiles(cfg)
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if certFile != "" {
+		scheme = "https"
 	}
+	s.warnFrontMatter()
 	address := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	logger("Server address:", "http://"+address+"/")
+	logger("Server address:", scheme+"://"+address+"/")
+	mux := http.NewServeMux()
 	if cfg.Watch {
-		if err := s.startLiveReloader(); err != nil {
+		if err := s.startLiveReloader(cfg.LiveReloadPort); err != nil {
 			return err
 		}
 		if err := s.watchReload(); err != nil {
 			return err
 		}
+		mux.Handle(liveReloadPrefix, s.lr)
 	}
-	http.HandleFunc("/", s.handler)
+	if cfg.DebugEndpoints {
+		mux.HandleFunc(debugPrefix, s.debugHandler)
+	}
+	mux.HandleFunc("/", s.handler)
 	c := make(chan error)
 	go func() {
-		c <- http.ListenAndServe(address, nil)
+		if certFile != "" {
+			c <- http.ListenAndServeTLS(address, certFile, keyFile, mux)
+			return
+		}
+		c <- http.ListenAndServe(address, mux)
 	}()
 	logger("Server running...", "press ctrl-c to stop.")
 	if open {
-		if err := browser.OpenURL("http://" + address); err != nil {
+		if err := browser.OpenURL(scheme + "://" + address); err != nil {
 			fmt.Println("Error opening page:", err)
 		}
 	}
@@ -61,11 +78,14 @@ func (s *Server) handler(rw http.ResponseWriter, r *http.Request) {
 	s.m.Lock()
 	defer s.m.Unlock()
 
-	var (
-		site     = s.Site
-		urlpath  = r.URL.Path
-		p, found = site.URLPage(urlpath)
-	)
+	urlpath := r.URL.Path
+	site, preview, err := s.requestSite(rw, r)
+	if err != nil {
+		s.writeError(rw, urlpath, err)
+		return
+	}
+	site, sitePath := site.LocalizedSite(urlpath)
+	p, found := site.URLPage(sitePath)
 	if !found {
 		rw.WriteHeader(http.StatusNotFound)
 		p, found = site.Routes["/404.html"]
@@ -83,25 +103,63 @@ func (s *Server) handler(rw http.ResponseWriter, r *http.Request) {
 	}
 	var w io.Writer = rw
 	if strings.HasPrefix(mimeType, "text/html;") {
-		w = NewLiveReloadInjector(w)
-	}
-	err := site.WriteDocument(w, p)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error rendering %s: %s\n", urlpath, err)
-		eng := liquid.NewEngine()
-		excerpt, path := fileErrorContext(err)
-		out, e := eng.ParseAndRenderString(renderErrorTemplate, liquid.Bindings{
-			"error":   fmt.Sprint(err),
-			"excerpt": excerpt,
-			"path":    path,
-			"watch":   site.Config().Watch,
-		})
-		if e != nil {
-			panic(e)
-		}
-		if _, err := io.WriteString(w, out); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
+		if s.lr != nil {
+			w = NewLiveReloadInjector(w, r)
 		}
+		w = TagInjector{w, previewBannerTag(preview)}
+	}
+	if err := site.WriteDocument(w, p); err != nil {
+		s.writeError(w, urlpath, err)
+	}
+}
+
+// warnFrontMatter prints the pages whose front matter doesn't match their
+// schema. build fails on these; serve keeps serving.
+func (s *Server) warnFrontMatter() {
+	err := s.Site.FrontMatterErrors()
+	if err == nil {
+		return
+	}
+	warnings := strings.Split(err.Error(), "\n")
+	if s.lastBuild != nil {
+		s.lastBuild.Warnings = warnings
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+}
+
+// writeError reports a rendering error to the console, the debug endpoints,
+// and the browser.
+func (s *Server) writeError(w io.Writer, urlpath string, err error) {
+	fmt.Fprintf(os.Stderr, "Error rendering %s: %s\n", urlpath, err)
+	if s.renderErrors == nil {
+		s.renderErrors = map[string]string{}
+	}
+	s.renderErrors[urlpath] = err.Error()
+	eng := liquid.NewEngine()
+	excerpt, path := fileErrorContext(err)
+	out, e := eng.ParseAndRenderString(renderErrorTemplate, liquid.Bindings{
+		"error":   fmt.Sprint(err),
+		"excerpt": excerpt,
+		"path":    path,
+		"watch":   s.Site.Config().Watch,
+	})
+	if e != nil {
+		panic(e)
+	}
+	if _, err := io.WriteString(w, out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
+	}
+}
+
+// clearAbsoluteURL makes the site's absolute URL relative, so that links
+// stay on the development server. JEKYLL_URL overrides this.
+func clearAbsoluteURL(site *site.Site) {
+	if jekyllURL := os.Getenv("JEKYLL_URL"); jekyllURL != "" {
+		site.SetAbsoluteURL(jekyllURL)
+	} else {
+		site.SetAbsoluteURL("")
 	}
 }
 
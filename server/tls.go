@@ -0,0 +1,157 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/config"
+)
+
+// selfSignedValidity is the lifetime of a generated certificate.
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// tlsFiles returns the certificate and key files that the server should use,
+// or empty strings if it should serve plain HTTP.
+func tlsFiles(cfg *config.Config) (certFile, keyFile string, err error) {
+	switch {
+	case cfg.SSLSelfSigned:
+		return selfSignedCertificate(filepath.Join(cache.Dir(), "tls"), cfg.Host)
+	case cfg.SSLCert != "" && cfg.SSLKey != "":
+		// As in Jekyll, these are relative to the source directory.
+		return sourcePath(cfg, cfg.SSLCert), sourcePath(cfg, cfg.SSLKey), nil
+	case cfg.SSLCert != "" || cfg.SSLKey != "":
+		return "", "", fmt.Errorf("--ssl-cert and --ssl-key must be used together")
+	}
+	return "", "", nil
+}
+
+func sourcePath(cfg *config.Config, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(cfg.Source, name)
+}
+
+// selfSignedCertificate returns a certificate and key for host, which it
+// keeps in dir. It re-uses the previous certificate unless that has expired
+// or doesn't name the host.
+func selfSignedCertificate(dir, host string) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	hosts := certificateHosts(host)
+	if certificateCovers(certFile, keyFile, hosts) {
+		return
+	}
+	certPEM, keyPEM, err := makeSelfSignedCertificate(hosts)
+	if err != nil {
+		return "", "", err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return "", "", err
+	}
+	if err = os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return "", "", err
+	}
+	if err = os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return "", "", err
+	}
+	return
+}
+
+// certificateHosts returns the host names and addresses that a certificate
+// for a server bound to host should name. A server bound to all interfaces is
+// reachable at each of this machine's addresses, e.g. from a phone on the LAN.
+func certificateHosts(host string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	switch host {
+	case "", "0.0.0.0", "::":
+		if name, err := os.Hostname(); err == nil {
+			hosts = append(hosts, name)
+		}
+		if addrs, err := net.InterfaceAddrs(); err == nil {
+			for _, addr := range addrs {
+				if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
+					hosts = append(hosts, ipnet.IP.String())
+				}
+			}
+		}
+	case "localhost", "127.0.0.1", "::1":
+	default:
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// certificateCovers returns true if the files hold a certificate that is valid
+// for at least another day, for each of hosts.
+func certificateCovers(certFile, keyFile string, hosts []string) bool {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return false
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return false
+	}
+	if time.Now().Add(24 * time.Hour).After(cert.NotAfter) {
+		return false
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func makeSelfSignedCertificate(hosts []string) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"gojekyll development server"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfSignedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, err := selfSignedCertificate(dir, "example.test")
+	require.NoError(t, err)
+	_, err = tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	require.True(t, certificateCovers(certFile, keyFile, []string{"localhost", "127.0.0.1", "example.test"}))
+	require.False(t, certificateCovers(certFile, keyFile, []string{"other.test"}))
+
+	// The certificate is cached
+	info, err := os.Stat(certFile)
+	require.NoError(t, err)
+	_, _, err = selfSignedCertificate(dir, "example.test")
+	require.NoError(t, err)
+	info2, err := os.Stat(certFile)
+	require.NoError(t, err)
+	require.Equal(t, info.ModTime(), info2.ModTime())
+
+	// A different host invalidates it
+	_, _, err = selfSignedCertificate(dir, "other.test")
+	require.NoError(t, err)
+	require.True(t, certificateCovers(certFile, keyFile, []string{"other.test"}))
+}
+
+func TestTLSFiles(t *testing.T) {
+	cfg := config.Default()
+	certFile, _, err := tlsFiles(&cfg)
+	require.NoError(t, err)
+	require.Equal(t, "", certFile)
+
+	cfg.Source = "site"
+	cfg.SSLCert = "cert.pem"
+	cfg.SSLKey = "/abs/key.pem"
+	certFile, keyFile, err := tlsFiles(&cfg)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("site", "cert.pem"), certFile)
+	require.Equal(t, "/abs/key.pem", keyFile)
+
+	cfg.SSLKey = ""
+	_, _, err = tlsFiles(&cfg)
+	require.Error(t, err)
+}
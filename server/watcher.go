@@ -9,30 +9,17 @@ import (
 )
 
 // Create a goroutine that rebuilds the site when files change.
+//
+// The watcher follows the site as reload replaces it, so it picks up
+// configuration changes to include, exclude, the theme, etc.
 func (s *Server) watchReload() error {
-	site := s.Site
-	// FIXME reload swaps in a new site but we're still watching the old one.
-	// This won't pick up changes to include, exclude, etc.
-	changes, err := site.WatchFiles()
+	changes, err := s.Site.WatchFiles()
 	if err != nil {
 		return err
 	}
 	go func() {
 		for change := range changes {
-			// Resolves filenames to URLS *before* reloading the site, in case the latter
-			// changes the url -> filename routes.
-			urls := map[string]bool{}
-			for _, rel := range change.Paths {
-				url, ok := site.FilenameURLPath(rel)
-				if ok {
-					urls[url] = true
-				}
-			}
-			if site.RequiresFullReload(change.Paths) {
-				for u := range site.Routes {
-					urls[u] = true
-				}
-			}
+			urls := s.changedURLs(change.Paths)
 			// reload the site
 			s.reload(change)
 			// tell the pages their files (may have) changed
@@ -44,6 +31,30 @@ func (s *Server) watchReload() error {
 	return nil
 }
 
+// changedURLs returns the URLs of the pages that depend on paths. It resolves
+// filenames to URLs *before* reloading the site, in case the latter changes
+// the url -> filename routes.
+func (s *Server) changedURLs(paths []string) map[string]bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	var (
+		site = s.Site
+		urls = map[string]bool{}
+	)
+	for _, rel := range paths {
+		url, ok := site.FilenameURLPath(rel)
+		if ok {
+			urls[url] = true
+		}
+	}
+	if site.RequiresFullReload(paths) {
+		for u := range site.Routes {
+			urls[u] = true
+		}
+	}
+	return urls
+}
+
 func (s *Server) reload(change site.FilesEvent) {
 	s.m.Lock()
 	defer s.m.Unlock()
@@ -51,19 +62,20 @@ func (s *Server) reload(change site.FilesEvent) {
 	// similar code to site.WatchRebuild
 	fmt.Printf("Re-reading: %v...", change)
 	start := time.Now()
+	s.lastBuild = &buildStatus{Time: start, Event: change.String(), Paths: change.Paths}
+	s.renderErrors = nil
+	defer func() { s.lastBuild.Duration = time.Since(start).Seconds() }()
 	site, err := s.Site.Reloaded(change.Paths)
 	if err != nil {
+		s.lastBuild.Error = err.Error()
 		fmt.Println()
 		fmt.Fprintln(os.Stderr, err.Error())
 		s.lr.Alert(fmt.Sprintf("Error reading site configuration: %s", err))
 		return
 	}
 	s.Site = site
-	// Only clear URL if JEKYLL_URL is not set
-	if jekyllURL := os.Getenv("JEKYLL_URL"); jekyllURL != "" {
-		s.Site.SetAbsoluteURL(jekyllURL)
-	} else {
-		s.Site.SetAbsoluteURL("")
-	}
+	s.preview = nil
+	clearAbsoluteURL(s.Site)
 	fmt.Printf("done (%.2fs)\n", time.Since(start).Seconds())
+	s.warnFrontMatter()
 }
@@ -1,18 +1,39 @@
 package site
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/BurntSushi/toml"
 	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
 )
 
+// readDataFiles reads the files in the data directory into s.data. A file
+// _data/team/alice.yml is site.data.team.alice. The themes' data
+// directories are read first, ancestors first, so that the site's data files
+// take precedence.
 func (s *Site) readDataFiles() error {
 	s.data = map[string]interface{}{}
+	s.dataSources = map[string]string{}
+	for i := len(s.themeDirs) - 1; i >= 0; i-- {
+		if err := s.readDataDir(filepath.Join(s.themeDirs[i], "_data"), "", s.data); err != nil {
+			return err
+		}
+	}
 	dataDir := filepath.Join(s.SourceDir(), s.cfg.DataDir)
-	files, err := os.ReadDir(dataDir)
+	return s.readDataDir(dataDir, "", s.data)
+}
+
+// readDataDir reads the files in dir into data, and its subdirectories into
+// nested maps. prefix is the dotted path of data within site.data.
+func (s *Site) readDataDir(dir, prefix string, data map[string]interface{}) error {
+	files, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
@@ -20,50 +41,141 @@ func (s *Site) readDataFiles() error {
 		return err
 	}
 	for _, f := range files {
-		if f.IsDir() {
-			break
-		}
 		var (
-			filename  = filepath.Join(dataDir, f.Name())
-			basename  = utils.TrimExt(filepath.Base(f.Name()))
-			data, err = readDataFile(filename)
+			filename = filepath.Join(dir, f.Name())
+			basename = utils.TrimExt(filepath.Base(f.Name()))
 		)
+		switch {
+		case strings.HasPrefix(f.Name(), "."):
+			continue
+		case f.IsDir():
+			m, ok := data[f.Name()].(map[string]interface{})
+			if !ok {
+				m = map[string]interface{}{}
+			}
+			if err := s.readDataDir(filename, prefix+f.Name()+".", m); err != nil {
+				return err
+			}
+			data[f.Name()] = m
+			continue
+		}
+		d, err := s.readDataFile(filename)
 		if err != nil {
-			return utils.WrapPathError(err, filename)
+			return err
 		}
-		if data != nil {
-			s.data[basename] = data
+		if d != nil {
+			data[basename] = mergeData(data[basename], d)
+			s.dataSources[prefix+basename] = filename
 		}
 	}
 	return nil
 }
 
-func readDataFile(filename string) (interface{}, error) {
-	switch filepath.Ext(filename) {
+// mergeData returns override, or if both base and override are maps, their
+// deep merge with the entries in override taking precedence.
+func mergeData(base, override interface{}) interface{} {
+	b, ok1 := base.(map[string]interface{})
+	o, ok2 := override.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return override
+	}
+	result := make(map[string]interface{}, len(b)+len(o))
+	for k, v := range b {
+		result[k] = v
+	}
+	for k, v := range o {
+		result[k] = mergeData(result[k], v)
+	}
+	return result
+}
+
+// readDataFile reads a CSV, TSV, JSON, TOML or YAML data file. It returns
+// nil for a file with another extension. Errors include the filename, and
+// the line number if the decoder provides one.
+func (s *Site) readDataFile(filename string) (interface{}, error) {
+	var decode func([]byte) (interface{}, error)
+	switch strings.ToLower(filepath.Ext(filename)) {
 	case ".csv":
-		f, err := os.Open(filename)
-		if err != nil {
-			return nil, err
-		}
-		defer f.Close() // nolint: errcheck
-		r := csv.NewReader(f)
-		return r.ReadAll()
+		decode = func(b []byte) (interface{}, error) { return s.readDelimited(b, ',') }
+	case ".tsv":
+		decode = func(b []byte) (interface{}, error) { return s.readDelimited(b, '\t') }
 	case ".json":
-		b, err := os.ReadFile(filename)
-		if err != nil {
-			return nil, err
+		decode = func(b []byte) (d interface{}, err error) {
+			err = json.Unmarshal(b, &d)
+			return
+		}
+	case ".toml":
+		decode = func(b []byte) (interface{}, error) {
+			var d map[string]interface{}
+			err := toml.Unmarshal(b, &d)
+			return d, err
 		}
-		var d interface{}
-		err = json.Unmarshal(b, &d)
-		return d, err
 	case ".yaml", ".yml":
-		b, err := os.ReadFile(filename)
-		if err != nil {
-			return nil, err
+		decode = func(b []byte) (d interface{}, err error) {
+			err = utils.UnmarshalYAMLInterface(b, &d)
+			return
+		}
+	default:
+		return nil, nil
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	d, err := decode(b)
+	var tomlErr toml.ParseError
+	switch {
+	case errors.As(err, &tomlErr):
+		return nil, utils.WrapPathLineError(errors.New(tomlErr.Message), filename, tomlErr.Position.Line)
+	case err != nil:
+		return nil, utils.WrapParseError(err, filename, b)
+	}
+	return d, nil
+}
+
+// readDelimited reads CSV or TSV. Like Jekyll, each row is a map from the
+// column headers in the first row to the row's values; unless the
+// csv_reader or tsv_reader configuration sets headers to false, in which
+// case each row is a list.
+func (s *Site) readDelimited(b []byte, comma rune) (interface{}, error) {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = comma
+	if comma == '\t' {
+		r.LazyQuotes = true
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	readerConfig := map[rune]string{',': "csv_reader", '\t': "tsv_reader"}[comma]
+	if !s.dataHeaders(readerConfig) {
+		return records, nil
+	}
+	rows := make([]interface{}, 0, len(records))
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		row := make(map[string]interface{}, len(record))
+		for j, value := range record {
+			if j < len(records[0]) {
+				row[records[0][j]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// dataHeaders returns the headers option of a csv_reader or tsv_reader
+// configuration.
+func (s *Site) dataHeaders(readerConfig string) bool {
+	if m, ok := s.cfg.Variables()[readerConfig].(yaml.MapSlice); ok {
+		for _, item := range m {
+			if headers, ok := item.Value.(bool); ok && item.Key == "headers" {
+				return headers
+			}
 		}
-		var d interface{}
-		err = utils.UnmarshalYAMLInterface(b, &d)
-		return d, err
 	}
-	return nil, nil
+	return true
 }
@@ -0,0 +1,62 @@
+package site
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDataSite(t *testing.T, files map[string]string) *Site {
+	s, err := FromDirectory(writeSiteFiles(t, files), config.Flags{})
+	require.NoError(t, err)
+	return s
+}
+
+func TestSite_readDataFiles(t *testing.T) {
+	s := writeDataSite(t, map[string]string{
+		"_data/team/alice.yml":    "name: Alice\n",
+		"_data/team/ops/bob.toml": "name = \"Bob\"\nroles = [\"admin\"]\n",
+		"_data/members.csv":       "name,role\nAlice,lead\nBob,ops\n",
+		"_data/scores.tsv":        "name\tscore\nAlice\t10\n",
+		"_data/.hidden/x.yml":     "x: 1\n",
+	})
+	require.NoError(t, s.readDataFiles())
+
+	team := s.data["team"].(map[string]interface{})
+	require.Equal(t, "Alice", team["alice"].(map[string]interface{})["name"])
+	bob := team["ops"].(map[string]interface{})["bob"].(map[string]interface{})
+	require.Equal(t, "Bob", bob["name"])
+	require.Equal(t, []interface{}{"admin"}, bob["roles"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"name": "Alice", "role": "lead"},
+		map[string]interface{}{"name": "Bob", "role": "ops"},
+	}, s.data["members"])
+	require.Equal(t, []interface{}{map[string]interface{}{"name": "Alice", "score": "10"}}, s.data["scores"])
+	require.NotContains(t, s.data, ".hidden")
+}
+
+func TestSite_readDataFiles_noHeaders(t *testing.T) {
+	s := writeDataSite(t, map[string]string{
+		"_config.yml":       "csv_reader:\n  headers: false\n",
+		"_data/members.csv": "name,role\nAlice,lead\n",
+	})
+	require.NoError(t, s.readDataFiles())
+	require.Equal(t, [][]string{{"name", "role"}, {"Alice", "lead"}}, s.data["members"])
+}
+
+func TestSite_readDataFiles_errors(t *testing.T) {
+	for name, content := range map[string]string{
+		"bad.yml":  "a: 1\nb: [\n",
+		"bad.json": "{\n\"a\": 1,\n}",
+		"bad.toml": "a = 1\nb = \n",
+		"bad.csv":  "a,b\n1,2\n3\n",
+	} {
+		s := writeDataSite(t, map[string]string{"_data/" + name: content})
+		err := s.readDataFiles()
+		require.Error(t, err, name)
+		require.Contains(t, err.Error(), filepath.Join("_data", name)+":", name)
+		require.Regexp(t, `:[0-9]+: `, err.Error(), name)
+	}
+}
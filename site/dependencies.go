@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/utils"
 )
 
@@ -38,14 +39,21 @@ func (s *Site) Exclude(siteRel string) bool {
 // static asset can cause pages to change if they reference its
 // variables.
 //
-// This function works on relative paths. It does not work for theme
-// sources.
+// This function works on relative paths, and on the paths that the file
+// watcher reports for theme sources. A change to the theme always requires a
+// full reload.
 func (s *Site) RequiresFullReload(paths []string) bool {
+	if len(s.languages) > 0 && len(paths) > 0 {
+		// the language sites don't rebuild incrementally
+		return true
+	}
 	for _, path := range paths {
 		switch {
-		case s.cfg.IsConfigPath(path):
+		case s.cfg.IsConfigPath(path), isSchemaPath(path):
+			return true
+		case s.isThemePath(path):
 			return true
-		case s.Exclude(path):
+		case s.Exclude(path) && !isNestedPostsPath(path):
 			continue
 		case !s.cfg.Incremental:
 			return true
@@ -73,11 +81,18 @@ func (s *Site) affectsBuildFilter(paths []string) []string {
 loop:
 	for _, path := range paths {
 		switch {
-		case s.cfg.IsConfigPath(path):
+		case seen[path]:
+			continue loop
+		case s.cfg.IsConfigPath(path), isSchemaPath(path):
 			// break
-		case !s.fileAffectsBuild(path):
+		case s.isThemePath(path):
+			if strings.HasPrefix(filepath.Base(path), ".") {
+				continue loop
+			}
+		case filepath.IsAbs(path):
+			// outside the site, and not in the current theme
 			continue loop
-		case seen[path]:
+		case !s.fileAffectsBuild(path):
 			continue loop
 		}
 		result = append(result, path)
@@ -86,6 +101,36 @@ loop:
 	return result
 }
 
+// isSchemaPath returns true if rel is a front matter schema file.
+func isSchemaPath(rel string) bool {
+	return strings.HasPrefix(filepath.ToSlash(rel), config.SchemasDir+"/")
+}
+
+// isNestedPostsPath returns true if rel is in, or is, a _posts or _drafts
+// directory below the top level, e.g. blog/_posts. Site.Exclude excludes
+// these, but the posts collection reads them.
+func isNestedPostsPath(rel string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, part := range parts {
+		if part == "_posts" || part == "_drafts" {
+			return i > 0
+		}
+		if strings.HasPrefix(part, "_") || strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return false
+}
+
+// isThemePath returns true if path is in a theme directory. path is either
+// relative to the site source, or absolute.
+func (s *Site) isThemePath(path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.AbsDir(), path)
+	}
+	return s.themeDirFor(path) != ""
+}
+
 // Returns true if the file or a parent directory is excluded.
 // Cf. Site.Exclude.
 func (s *Site) fileAffectsBuild(rel string) bool {
@@ -1,13 +1,44 @@
 package site
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/stretchr/testify/require"
 )
 
-//func TestSite_WatchRebuild(t *testing.T) {
+func TestSite_WatchRebuild(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "index.md")
+	require.NoError(t, os.WriteFile(page, []byte("---\n---\nfirst\n"), 0644))
+	s, err := FromDirectory(dir, config.Flags{ForcePolling: true})
+	require.NoError(t, err)
+
+	// as in build --watch: watch, read, write, and only then rebuild
+	_, err = s.WatchFiles()
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	// a change during the first build
+	require.NoError(t, os.WriteFile(page, []byte("---\n---\nsecond\n"), 0644))
+	_, err = s.Write()
+	require.NoError(t, err)
+	time.Sleep(time.Second) // the change is now buffered
+
+	messages, err := s.WatchRebuild()
+	require.NoError(t, err)
+	select {
+	case <-messages: // Regenerating...
+		<-messages // wrote...
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the rebuild")
+	}
+	b, err := os.ReadFile(filepath.Join(s.DestDir(), "index.html"))
+	require.NoError(t, err)
+	require.Contains(t, string(b), "second")
+}
 
 func TestSite_Reloaded(t *testing.T) {
 	s0 := New(config.Flags{})
@@ -20,7 +51,35 @@ func TestSite_Reloaded(t *testing.T) {
 }
 
 //func TestSite_processFilesEvent(t *testing.T) {
-//func TestSite_rebuild(t *testing.T) {
+
+func TestSite_rebuild_removed(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "_posts"), 0755))
+	for _, name := range []string{"2020-01-01-a.md", "2020-01-02-b.md", "2020-01-03-c.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "_posts", name), []byte("---\n---\n{{ page.title }}\n"), 0644))
+	}
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	s.cfg.Incremental = true
+	require.NoError(t, s.Read())
+	_, err = s.Write()
+	require.NoError(t, err)
+	output := filepath.Join(s.DestDir(), "2020", "01", "02", "b.html")
+	require.FileExists(t, output)
+
+	rel := filepath.Join("_posts", "2020-01-02-b.md")
+	require.NoError(t, os.Remove(filepath.Join(dir, rel)))
+	r, n, err := s.rebuild(FilesEvent{Paths: []string{rel}, Ops: map[string]FileOp{rel: FileRemove}})
+	require.NoError(t, err)
+	require.Equal(t, s, r)
+	require.Equal(t, 2, n) // the neighbors
+	require.NoFileExists(t, output)
+	require.NotContains(t, s.Routes, "/2020/01/02/b.html")
+	posts := s.Posts()
+	require.Len(t, posts, 2)
+	require.Equal(t, posts[1], posts[0].FrontMatter()["next"])
+	require.Equal(t, posts[0], posts[1].FrontMatter()["previous"])
+}
 
 func TestSite_RequiresFullReload(t *testing.T) {
 	s := New(config.Flags{})
@@ -39,3 +98,11 @@ func TestSite_RequiresFullReload(t *testing.T) {
 //func TestSite_affectsBuildFilter(t *testing.T) {
 //func TestSite_fileAffectsBuild(t *testing.T) {
 //func TestSite_invalidatesDoc(t *testing.T) {
+
+func Test_isNestedPostsPath(t *testing.T) {
+	require.True(t, isNestedPostsPath("blog/_posts"))
+	require.True(t, isNestedPostsPath("blog/news/_drafts/2017-01-01-a.md"))
+	require.False(t, isNestedPostsPath("_posts/2017-01-01-a.md"))
+	require.False(t, isNestedPostsPath("_other/_posts/2017-01-01-a.md"))
+	require.False(t, isNestedPostsPath("blog/index.md"))
+}
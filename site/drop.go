@@ -39,6 +39,10 @@ func (s *Site) initializeDrop() error {
 	for _, c := range s.Collections {
 		drop[c.Name] = c.Pages()
 	}
+	if s.cfg.IsLocalized() {
+		drop["active_lang"] = s.cfg.ActiveLanguage()
+		drop["default_lang"] = s.cfg.DefaultLanguage()
+	}
 	s.drop = drop
 	s.setPostVariables()
 	return s.runHooks(func(h plugins.Plugin) error {
@@ -70,3 +70,30 @@ func TestSite_ToLiquid_static_files(t *testing.T) {
 	require.IsType(t, time.Now(), f["modified_time"])
 	require.Equal(t, ".html", f["extname"])
 }
+
+func TestSite_ToLiquid_static_file_defaults(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml": `defaults:
+  - scope: {path: "**/*.pdf"}
+    values: {sitemap: false}
+  - scope: {path: "", type: pages}
+    values: {layout: page}
+`,
+		"assets/paper.pdf": "%PDF",
+		"index.md":         "---\n---\n",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+
+	f, ok := s.Routes["/assets/paper.pdf"].(*pages.StaticFile)
+	require.True(t, ok)
+	drop := f.ToLiquid().(tags.IterationKeyedMap)
+	require.Equal(t, false, drop["sitemap"])
+	require.Equal(t, "/assets/paper.pdf", drop["path"])
+	require.NotContains(t, drop, "layout")
+
+	p, ok := s.Routes["/index.html"].(Page)
+	require.True(t, ok)
+	require.Equal(t, "page", p.FrontMatter()["layout"])
+}
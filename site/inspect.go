@@ -0,0 +1,168 @@
+package site
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+	"github.com/osteele/liquid"
+)
+
+// RouteTable returns a map of URL paths to source filenames. If dynamic is
+// true, it omits static files.
+//
+// The routes command and the server's debug endpoints use this.
+func (s *Site) RouteTable(dynamic bool) map[string]string {
+	routes := map[string]string{}
+	for u, p := range s.Routes {
+		if !dynamic || !p.IsStatic() {
+			routes[u] = p.Source()
+		}
+	}
+	return routes
+}
+
+// PathDocument returns a document, given a URL path or a file path.
+//
+// If path starts with /, it's a URL path. Else it's a file path relative
+// to the site source directory.
+func (s *Site) PathDocument(path string) (Document, error) {
+	if path == "" {
+		path = "/"
+	}
+	switch {
+	case strings.HasPrefix(path, "/"):
+		page, found := s.URLPage(path)
+		if !found {
+			return nil, utils.NewPathError("render", path, "the site does not include a file with this URL path")
+		}
+		return page, nil
+	default:
+		page, found := s.FilePathPage(path)
+		if !found {
+			return nil, utils.NewPathError("render", path, "no such file")
+		}
+		return page, nil
+	}
+}
+
+// PathVariables returns the template variables for path, which is a
+// filename, a URL path, "site", or a site variable such as "site.x.y". If
+// path is empty, it returns the site variables.
+//
+// The variables command and the server's debug endpoints use this.
+func (s *Site) PathVariables(path string) (data interface{}, err error) {
+	var names []string
+	switch {
+	case strings.HasPrefix(path, "site"):
+		names = strings.Split(path, ".")[1:]
+		data, err = utils.FollowDots(s, names)
+		if err != nil {
+			return
+		}
+	case path != "":
+		data, err = s.PathDocument(path)
+		if err != nil {
+			return
+		}
+	default:
+		data = s
+	}
+	data = liquid.FromDrop(data)
+	if names != nil || path == "" {
+		data = s.redactEnvReferences(data, names)
+	}
+	bytesToStrings(data)
+	return
+}
+
+// redactEnvReferences returns data, the value of the site variable at names,
+// with the configuration values that were read from environment variables
+// replaced by the text that refers to them. The server's debug endpoints
+// show these, so they mustn't reveal secrets.
+func (s *Site) redactEnvReferences(data interface{}, names []string) interface{} {
+	refs := s.cfg.EnvReferences()
+	if len(names) > 0 {
+		ref, ok := refs[names[0]]
+		if !ok {
+			return data
+		}
+		ref = utils.ConvertYAMLValue(ref)
+		if v, err := utils.FollowDots(ref, names[1:]); err == nil {
+			return v
+		}
+		return ref
+	}
+	rv := reflect.ValueOf(data)
+	if len(refs) == 0 || rv.Kind() != reflect.Map {
+		return data
+	}
+	m := make(map[string]interface{}, rv.Len())
+	for _, k := range rv.MapKeys() {
+		m[k.String()] = rv.MapIndex(k).Interface()
+	}
+	for k, ref := range refs {
+		if _, ok := m[k]; ok {
+			m[k] = utils.ConvertYAMLValue(ref)
+		}
+	}
+	return m
+}
+
+// VariableSources returns where the site variables that PathVariables
+// returns for path came from, keyed by variable name such as "site.title" or
+// "site.data.team.alice". A configuration variable's source is its layer,
+// with the file that set it; a data variable's is its data file. Files are
+// relative to the site source directory if they're inside it. It returns nil
+// if path isn't a site variable path.
+//
+// The variables command uses this.
+func (s *Site) VariableSources(path string) map[string]string {
+	if path != "" && path != "site" && !strings.HasPrefix(path, "site.") {
+		return nil
+	}
+	sources := map[string]string{}
+	add := func(name, source string) {
+		if path == "" || path == "site" || name == path ||
+			strings.HasPrefix(name, path+".") || strings.HasPrefix(path, name+".") {
+			sources[name] = source
+		}
+	}
+	for key := range s.cfg.Variables() {
+		src := s.cfg.KeySource(key)
+		if src.File != "" {
+			src.File = s.sourcePath(src.File)
+		}
+		add("site."+key, src.String())
+	}
+	for name, filename := range s.dataSources {
+		add("site.data."+name, s.sourcePath(filename))
+	}
+	return sources
+}
+
+// sourcePath returns filename relative to the site source directory, if
+// it's inside it.
+func (s *Site) sourcePath(filename string) string {
+	rel, err := filepath.Rel(s.AbsDir(), utils.MustAbs(filename))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filename
+	}
+	return rel
+}
+
+// modifies its argument
+func bytesToStrings(data interface{}) {
+	if m, ok := data.(map[string]interface{}); ok {
+		for k, v := range m {
+			if b, ok := v.([]byte); ok {
+				s := string(b)
+				if len(s) > 200 {
+					s = s[:200] + "…"
+				}
+				m[k] = s
+			}
+		}
+	}
+}
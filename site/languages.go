@@ -0,0 +1,158 @@
+package site
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+)
+
+// A localized site, in the style of jekyll-polyglot, is built once for each
+// of its languages. The default language build is the site itself; the
+// other languages are separate sites, that write to a subdirectory of the
+// destination and serve under a URL prefix such as /de/.
+
+// readLanguages reads a site for each language other than the default.
+func (s *Site) readLanguages() error {
+	s.languages = nil
+	if !s.cfg.IsLocalized() || s.cfg.ActiveLang != "" {
+		return nil
+	}
+	s.languages = map[string]*Site{}
+	for _, lang := range s.cfg.Languages {
+		if lang == s.cfg.DefaultLanguage() {
+			continue
+		}
+		l, err := FromDirectory(s.SourceDir(), s.flags)
+		if err != nil {
+			return err
+		}
+		l.cfg.ActiveLang = lang
+		l.cfg.Destination = filepath.Join(s.DestDir(), lang)
+		if l.cfg.AbsoluteURL != s.cfg.AbsoluteURL {
+			l.SetAbsoluteURL(s.cfg.AbsoluteURL)
+		}
+		if err := l.Read(); err != nil {
+			return utils.WrapError(err, "reading language "+lang)
+		}
+		s.languages[lang] = l
+	}
+	return nil
+}
+
+// languageSites returns the sites for the languages other than the default,
+// ordered by language.
+func (s *Site) languageSites() []*Site {
+	langs := make([]string, 0, len(s.languages))
+	for lang := range s.languages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	sites := make([]*Site, len(langs))
+	for i, lang := range langs {
+		sites[i] = s.languages[lang]
+	}
+	return sites
+}
+
+// LocalizedSite returns the site that serves urlpath, and urlpath relative
+// to that site. For a localized site, this is the language site for a path
+// that starts with its prefix, such as /de/about/.
+func (s *Site) LocalizedSite(urlpath string) (*Site, string) {
+	for lang, l := range s.languages {
+		prefix := "/" + lang
+		if urlpath == prefix || strings.HasPrefix(urlpath, prefix+"/") {
+			return l, "/" + strings.TrimPrefix(urlpath[len(prefix):], "/")
+		}
+	}
+	return s, urlpath
+}
+
+// documentLanguage returns a document's lang front matter variable.
+func documentLanguage(d Document) string {
+	if p, ok := d.(Page); ok {
+		return p.FrontMatter().String("lang", "")
+	}
+	return ""
+}
+
+// selectLanguage removes the documents that aren't in the active language.
+// A document in the default language, or without a language, stands in for
+// a translation with the same URL that is missing.
+func (s *Site) selectLanguage() {
+	var (
+		active     = s.cfg.ActiveLanguage()
+		defaultLng = s.cfg.DefaultLanguage()
+		translated = map[string]Document{} // URL -> document in the active language
+	)
+	for _, d := range s.docs {
+		if documentLanguage(d) == active {
+			translated[d.URL()] = d
+		}
+	}
+	removed := func(d Document) bool {
+		switch lang := documentLanguage(d); {
+		case lang == active:
+			return false
+		case lang != "" && lang != defaultLng:
+			return true
+		case translated[d.URL()] != nil:
+			return true
+		case d.IsStatic() && active != defaultLng:
+			return s.cfg.IsExcludedFromLocalization(utils.MustRel(s.SourceDir(), d.Source()))
+		default:
+			return false
+		}
+	}
+	// The translation takes over the route of the document it replaces.
+	for u, d := range s.Routes {
+		if removed(d) {
+			if t := translated[u]; t != nil {
+				s.Routes[u] = t
+			}
+		}
+	}
+	s.removeDocuments(removed)
+}
+
+var hrefMatcher = regexp.MustCompile(`(href=")([^"]*)(")`)
+
+// localizeLinks adds the language prefix to links to the site's pages, in
+// the output of a language site. It leaves links to paths that are
+// excluded from localization alone.
+func (s *Site) localizeLinks(b []byte) []byte {
+	var (
+		lang    = s.cfg.ActiveLang
+		baseurl = strings.TrimSuffix(s.cfg.BaseURL, "/")
+		prefix  = "/" + lang
+	)
+	return hrefMatcher.ReplaceAllFunc(b, func(m []byte) []byte {
+		parts := hrefMatcher.FindSubmatch(m)
+		href := string(parts[2])
+		host := ""
+		if s.cfg.AbsoluteURL != "" && strings.HasPrefix(href, s.cfg.AbsoluteURL+"/") {
+			host = s.cfg.AbsoluteURL
+		}
+		rel := strings.TrimPrefix(href, host)
+		if !strings.HasPrefix(rel, baseurl+"/") || strings.HasPrefix(rel, "//") {
+			return m
+		}
+		rel = rel[len(baseurl):]
+		urlpath := rel
+		if i := strings.IndexAny(urlpath, "?#"); i >= 0 {
+			urlpath = urlpath[:i]
+		}
+		switch {
+		case urlpath == prefix || strings.HasPrefix(urlpath, prefix+"/"):
+			return m
+		case s.cfg.IsExcludedFromLocalization(urlpath):
+			return m
+		}
+		if _, found := s.URLPage(urlpath); !found {
+			return m
+		}
+		return []byte(string(parts[1]) + host + baseurl + prefix + rel + string(parts[3]))
+	})
+}
@@ -0,0 +1,56 @@
+package site
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_languages(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml":  "languages: [en, de]\ndefault_lang: en\nexclude_from_localization: [images]\n",
+		"about.md":     "---\nlang: en\npermalink: /about/\n---\n{{ site.active_lang }} <a href=\"/contact/\">c</a> <a href=\"/images/x.png\">i</a>",
+		"about-de.md":  "---\nlang: de\npermalink: /about/\n---\n{{ site.active_lang }} <a href=\"/contact/\">c</a> <a href=\"/images/x.png\">i</a> <a href=\"/missing/\">m</a>",
+		"contact.md":   "---\npermalink: /contact/\n---\ncontact",
+		"images/x.png": "png",
+	})
+	dest := filepath.Join(t.TempDir(), "_site")
+	s, err := FromDirectory(dir, config.Flags{Destination: &dest})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	render := func(s *Site, url string) string {
+		d, found := s.URLPage(url)
+		require.True(t, found, url)
+		buf := new(bytes.Buffer)
+		require.NoError(t, s.WriteDocument(buf, d))
+		return buf.String()
+	}
+
+	require.Contains(t, s.Routes, "/contact/")
+	require.Contains(t, s.Routes, "/images/x.png")
+	en := render(s, "/about/")
+	require.Contains(t, en, "en ")
+	require.Contains(t, en, `href="/contact/"`)
+
+	de, path := s.LocalizedSite("/de/about/")
+	require.NotEqual(t, s, de)
+	require.Equal(t, "/about/", path)
+	require.Contains(t, de.Routes, "/contact/", "falls back to the default language")
+	require.NotContains(t, de.Routes, "/images/x.png", "excluded from localization")
+	out := render(de, "/about/")
+	require.Contains(t, out, "de ")
+	require.Contains(t, out, `href="/de/contact/"`)
+	require.Contains(t, out, `href="/images/x.png"`)
+	require.Contains(t, out, `href="/missing/"`)
+
+	_, err = s.Write()
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dest, "about", "index.html"))
+	require.FileExists(t, filepath.Join(dest, "images", "x.png"))
+	require.FileExists(t, filepath.Join(dest, "de", "about", "index.html"))
+	require.FileExists(t, filepath.Join(dest, "de", "contact", "index.html"))
+	require.NoFileExists(t, filepath.Join(dest, "de", "images", "x.png"))
+}
@@ -5,9 +5,11 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/osteele/gojekyll/collection"
 	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/frontmatter"
 	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/plugins"
 	"github.com/osteele/gojekyll/utils"
@@ -16,21 +18,42 @@ import (
 // FromDirectory reads the configuration file, if it exists.
 func FromDirectory(dir string, flags config.Flags) (*Site, error) {
 	s := New(flags)
+	s.cfg.SetEnvAccess(flags.Safe, flags.EnvAllowlist)
 	if err := s.cfg.FromDirectory(dir, flags.Environment, flags.AdminFile, flags.ConfigFiles); err != nil {
 		return nil, utils.WrapError(err, "reading site")
 	}
 	s.cfg.ApplyFlags(s.flags)
+	// This also happens in Read; doing it here too means that a file watcher
+	// started before Read watches the same files as one started after.
+	s.excludeDestination()
 	return s, nil
 }
 
+// excludeDestination excludes the destination directory from source reading,
+// matching Ruby Jekyll behavior. Without this, rebuilds can read prior output
+// files as source documents.
+func (s *Site) excludeDestination() {
+	destRel, err := filepath.Rel(s.SourceDir(), s.DestDir())
+	switch {
+	case err != nil, destRel == ".", strings.HasPrefix(destRel, ".."):
+		// not inside the source directory
+	case utils.StringArrayContains(s.cfg.Exclude, destRel):
+		// Reloaded can read the same site more than once
+	default:
+		s.cfg.Exclude = append(s.cfg.Exclude, destRel)
+	}
+}
+
 // Read loads the site data and files.
 func (s *Site) Read() error {
-	if err := s.installPlugins(); err != nil {
-		return utils.WrapError(err, "initializing plugins")
-	}
+	s.readTime = time.Now()
+	// Reloaded can read the same site again; start from scratch.
 	s.Routes = make(map[string]Document)
-	if err := s.findTheme(); err != nil {
-		return utils.WrapError(err, "finding theme")
+	s.docs, s.nonCollectionPages = nil, nil
+	s.schemaErrors = nil
+	s.resetCaches()
+	if err := s.ReadConfig(); err != nil {
+		return err
 	}
 	if err := s.readDataFiles(); err != nil {
 		return utils.WrapError(err, "reading data files")
@@ -38,11 +61,12 @@ func (s *Site) Read() error {
 	if err := s.readThemeAssets(); err != nil {
 		return utils.WrapError(err, "reading theme assets")
 	}
-	// Exclude the destination directory from source reading, matching Ruby Jekyll behavior.
-	// Without this, rebuilds can read prior output files as source documents.
-	if destRel, err := filepath.Rel(s.SourceDir(), s.DestDir()); err == nil && destRel != "." && !strings.HasPrefix(destRel, "..") {
-		s.cfg.Exclude = append(s.cfg.Exclude, destRel)
+	s.excludeDestination()
+	schema, err := frontmatter.ReadSchemaFile(s.cfg.SchemaFile("pages"))
+	if err != nil {
+		return utils.WrapError(err, "reading the pages schema")
 	}
+	s.pagesSchema = schema
 	if err := s.readFiles(s.SourceDir(), s.SourceDir()); err != nil {
 		return utils.WrapError(err, "reading files")
 	}
@@ -60,7 +84,36 @@ func (s *Site) Read() error {
 			return err
 		}
 	}
-	return s.runHooks(func(p plugins.Plugin) error { return p.PostReadSite(s) })
+	if err := s.runHooks(func(p plugins.Plugin) error { return p.PostReadSite(s) }); err != nil {
+		return err
+	}
+	if s.cfg.IsLocalized() {
+		s.selectLanguage()
+		if err := s.readLanguages(); err != nil {
+			return err
+		}
+	}
+	if s.watcher != nil {
+		// The configuration and theme determine which files to watch.
+		return s.watcher.rescope(s)
+	}
+	return nil
+}
+
+// ReadConfig completes the configuration: it installs the plugins, which can
+// modify it, and merges the theme configuration beneath it. Read does this;
+// the config command does only this.
+func (s *Site) ReadConfig() error {
+	if err := s.installPlugins(); err != nil {
+		return utils.WrapError(err, "initializing plugins")
+	}
+	if err := s.findTheme(); err != nil {
+		return utils.WrapError(err, "finding theme")
+	}
+	if err := s.readThemeConfig(); err != nil {
+		return utils.WrapError(err, "reading theme configuration")
+	}
+	return nil
 }
 
 // isIncludedPath checks if a path or its parent directory is explicitly in the include list
@@ -74,6 +127,20 @@ func (s *Site) isIncludedPath(siteRel string) bool {
 	return false
 }
 
+// inCollectionDir returns true if the site-relative path rel is inside a
+// collection directory, or the posts drafts directory.
+func (s *Site) inCollectionDir(rel string) bool {
+	dir := strings.Split(filepath.ToSlash(rel), "/")[0]
+	if dir == rel || !strings.HasPrefix(dir, "_") {
+		return false
+	}
+	if dir == "_drafts" {
+		dir = "_posts"
+	}
+	_, ok := s.cfg.Collections[dir[1:]]
+	return ok
+}
+
 // readFiles scans the source directory and creates pages and collection.
 func (s *Site) readFiles(dir, base string) error {
 	return filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
@@ -84,15 +151,18 @@ func (s *Site) readFiles(dir, base string) error {
 		switch {
 		case info.IsDir() && s.Exclude(rel):
 			return filepath.SkipDir
-	  case info.IsDir():
-	  	return nil
-	  case s.Exclude(rel):
-	  	return nil
+		case info.IsDir():
+			return nil
+		case s.Exclude(rel):
+			return nil
 		}
-		defaultFrontmatter := s.cfg.GetFrontMatterDefaults("", rel)
-		d, err := pages.NewFile(s, filename, filepath.ToSlash(rel), defaultFrontmatter)
-		if err != nil {
+		d, err := pages.NewSiteFile(s, filename, filepath.ToSlash(rel))
+		switch {
+		case err != nil:
 			return utils.WrapPathError(err, filename)
+		case d.IsStatic() && s.inCollectionDir(rel):
+			// ReadCollections reads these, with the collection's permalinks.
+			return nil
 		}
 		s.AddDocument(d, true)
 		if p, ok := d.(Page); ok {
@@ -102,6 +172,13 @@ func (s *Site) readFiles(dir, base string) error {
 			dir := filepath.Dir(rel)
 			if dir == "." || !strings.HasPrefix(filepath.Base(dir), "_") || s.isIncludedPath(rel) {
 				s.nonCollectionPages = append(s.nonCollectionPages, p)
+				// readThemeAssets reads theme files with this too; those
+				// aren't the site's pages.
+				if s.pagesSchema != nil && base == s.SourceDir() {
+					for _, err := range s.pagesSchema.Validate(p.FrontMatter()) {
+						s.schemaErrors = append(s.schemaErrors, utils.WrapPathError(err, filename))
+					}
+				}
 			}
 		}
 		return nil
@@ -133,6 +210,10 @@ func (s *Site) ReadCollections() (err error) {
 		for _, p := range c.Pages() {
 			s.AddDocument(p, c.Output())
 		}
+		for _, f := range c.StaticFiles() {
+			s.AddDocument(f, c.Output())
+		}
+		s.schemaErrors = append(s.schemaErrors, c.SchemaErrors()...)
 	}
 	sort.Slice(cols, func(i, j int) bool {
 		return cols[i].Name < cols[j].Name
@@ -140,3 +221,13 @@ func (s *Site) ReadCollections() (err error) {
 	s.Collections = cols
 	return nil
 }
+
+// FrontMatterErrors returns an error that lists the pages whose front matter
+// doesn't match the schema of their collection, or of the site's pages; or
+// nil if they all do. build fails with this error; serve prints it as
+// warnings.
+func (s *Site) FrontMatterErrors() error {
+	errs := append([]error{}, s.schemaErrors...)
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return combineErrors(errs)
+}
@@ -3,8 +3,12 @@ package site
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/utils"
 )
 
@@ -12,6 +16,10 @@ import (
 // rebuilds the site. It sends status messages (strings) and errors to its output
 // channel.
 //
+// If WatchFiles was called before Read, WatchRebuild first replays the
+// changes that the watcher buffered since then, skipping those that Read
+// has already seen.
+//
 // TODO use a logger instead of a message channel?
 func (s *Site) WatchRebuild() (<-chan interface{}, error) {
 	var (
@@ -23,6 +31,10 @@ func (s *Site) WatchRebuild() (<-chan interface{}, error) {
 	}
 	go func() {
 		for fileset := range filesets {
+			if !fileset.Time.After(s.readTime) {
+				// all the changes precede the read
+				continue
+			}
 			s = s.processFilesEvent(fileset, messages)
 		}
 	}()
@@ -37,21 +49,42 @@ func (s *Site) Reloaded(paths []string) (*Site, error) {
 		if err != nil {
 			return nil, err
 		}
+		copy.watcher = s.watcher
 		s = copy
 	}
 	return s, s.Read()
 }
 
+// PreviewSite returns a new site that reads the same source directory,
+// configuration file, and load flags, but that also includes drafts, future
+// posts and unpublished posts. serve uses this to preview these per request.
+func (s *Site) PreviewSite() (*Site, error) {
+	var (
+		flags = s.flags
+		t     = true
+	)
+	flags.Drafts, flags.Future, flags.Unpublished = &t, &t, &t
+	p, err := FromDirectory(s.SourceDir(), flags)
+	if err != nil {
+		return nil, err
+	}
+	return p, p.Read()
+}
+
 func (s *Site) processFilesEvent(fileset FilesEvent, messages chan<- interface{}) *Site {
 	// similar code to server.reload
 	messages <- fmt.Sprintf("Regenerating: %s...", fileset)
 	start := time.Now()
-	r, count, err := s.rebuild(fileset.Paths)
+	r, count, err := s.rebuild(fileset)
 	if err != nil {
 		fmt.Println()
 		fmt.Fprintln(os.Stderr, err)
 		return s
 	}
+	if err := r.FrontMatterErrors(); err != nil {
+		fmt.Println()
+		fmt.Fprintln(os.Stderr, err)
+	}
 	elapsed := time.Since(start)
 	inflect := map[bool]string{true: "", false: "s"}[count == 1]
 	messages <- fmt.Sprintf("wrote %d file%s in %.2fs.\n", count, inflect, elapsed.Seconds())
@@ -59,8 +92,13 @@ func (s *Site) processFilesEvent(fileset FilesEvent, messages chan<- interface{}
 }
 
 // reloads and rebuilds the site; returns a copy and count
-func (s *Site) rebuild(paths []string) (r *Site, n int, err error) {
-	if s.RequiresFullReload(paths) {
+//
+// In incremental mode, this rewrites the pages whose sources changed, and
+// removes the output of deleted and renamed sources. A new source requires
+// a full reload, since it can change other pages' variables.
+func (s *Site) rebuild(e FilesEvent) (r *Site, n int, err error) {
+	paths := e.Paths
+	if s.RequiresFullReload(paths) || s.addsSources(e) {
 		r, err = s.Reloaded(paths)
 		if err != nil {
 			return
@@ -69,19 +107,124 @@ func (s *Site) rebuild(paths []string) (r *Site, n int, err error) {
 		return
 	}
 	r = s
-	pathSet := utils.MakeStringSet(paths)
+	neighbors, err := s.removeSources(e.Removed())
+	if err != nil {
+		return
+	}
+	var (
+		pathSet  = utils.MakeStringSet(paths)
+		reloaded = map[Document]bool{}
+	)
 	for _, d := range s.docs {
 		if s.invalidatesDoc(pathSet, d) {
 			err = d.Reload()
 			if err != nil {
 				return
 			}
-			err = s.WriteDoc(d)
-			if err != nil {
-				return
-			}
-			n++
+			reloaded[d] = true
+		}
+	}
+	if len(reloaded) > 0 {
+		// A post's content, tags and categories determine the related
+		// posts.
+		s.resetRelatedPosts()
+	}
+	for _, d := range s.docs {
+		if !reloaded[d] && !neighbors[d] {
+			continue
+		}
+		if s.Routes[d.URL()] != d {
+			continue
+		}
+		err = s.WriteDoc(d)
+		if err != nil {
+			return
 		}
+		n++
 	}
 	return
 }
+
+// addsSources returns true if e creates a file.
+func (s *Site) addsSources(e FilesEvent) bool {
+	for _, path := range e.Paths {
+		if e.Op(path) == FileCreate {
+			return true
+		}
+	}
+	return false
+}
+
+// removeSources removes the documents whose sources are in paths – or, for
+// a directory, inside them – and their output files. It returns the
+// remaining documents whose previous or next links changed as a result.
+func (s *Site) removeSources(paths []string) (map[Document]bool, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	removed := func(d Document) bool {
+		rel := utils.MustRel(s.SourceDir(), d.Source())
+		for _, path := range paths {
+			if rel == path || strings.HasPrefix(rel, path+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, d := range s.docs {
+		if removed(d) && s.Routes[d.URL()] == d {
+			if err := s.removeOutput(d); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s.removeDocuments(removed), nil
+}
+
+// removeDocuments removes the documents for which removed returns true from
+// the site's documents, routes and collections. It returns the remaining
+// documents whose previous or next links changed as a result.
+func (s *Site) removeDocuments(removed func(Document) bool) map[Document]bool {
+	var (
+		docs      = make([]Document, 0, len(s.docs))
+		neighbors = map[Document]bool{}
+	)
+	for _, d := range s.docs {
+		if !removed(d) {
+			docs = append(docs, d)
+			continue
+		}
+		if s.Routes[d.URL()] == d {
+			delete(s.Routes, d.URL())
+		}
+		switch d := d.(type) {
+		case Page:
+			for _, c := range s.Collections {
+				for _, q := range c.RemovePage(d) {
+					neighbors[q] = true
+				}
+			}
+		case *pages.StaticFile:
+			for _, c := range s.Collections {
+				c.RemoveStaticFile(d)
+			}
+		}
+	}
+	s.docs = docs
+	var pages []Page
+	for _, p := range s.nonCollectionPages {
+		if !removed(p) {
+			pages = append(pages, p)
+		}
+	}
+	s.nonCollectionPages = pages
+	for d := range neighbors {
+		if removed(d) {
+			delete(neighbors, d)
+		}
+	}
+	s.drop = nil
+	s.dropOnce = sync.Once{}
+	s.resetRelatedPosts()
+	return neighbors
+}
@@ -0,0 +1,266 @@
+package site
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/frontmatter"
+	"github.com/osteele/gojekyll/utils"
+	"github.com/osteele/liquid"
+)
+
+// maxRelatedPosts is the length of site.related_posts.
+const maxRelatedPosts = 10
+
+// SiteVariable is part of the pages.PageSite interface. While a post is
+// rendered, site.related_posts are the posts related to it.
+func (s *Site) SiteVariable(p Page) interface{} {
+	drop := s.ToLiquid()
+	if !p.IsPost() {
+		return drop
+	}
+	related := s.RelatedPosts(p)
+	if related == nil {
+		return drop
+	}
+	m := make(map[string]interface{}, len(s.drop)+1)
+	for k, v := range s.drop {
+		m[k] = v
+	}
+	m["related_posts"] = related
+	return liquid.IterationKeyedMap(m)
+}
+
+// RelatedPosts returns the posts that are most similar to p, most similar
+// first. Posts that are equally similar are ordered by date, newest first.
+//
+// By default, similarity is the number of shared tags and categories. With
+// the lsi option, it is the cosine similarity of the posts' TF-IDF vectors.
+func (s *Site) RelatedPosts(p Page) []Page {
+	s.relatedOnce.Do(func() {
+		var err error
+		s.related, err = s.computeRelatedPosts()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s; ranking related posts by tags\n", err)
+			s.related = relatedByTaxonomy(s.Posts())
+		}
+	})
+	return s.related[p]
+}
+
+// resetRelatedPosts forgets the related posts, so that RelatedPosts
+// recomputes them from the current posts.
+func (s *Site) resetRelatedPosts() {
+	s.related = nil
+	s.relatedOnce = sync.Once{}
+}
+
+func (s *Site) computeRelatedPosts() (map[Page][]Page, error) {
+	posts := s.Posts()
+	if !s.cfg.LSI {
+		return relatedByTaxonomy(posts), nil
+	}
+	return s.relatedByLSI(posts)
+}
+
+// relatedByTaxonomy ranks posts by the number of tags and categories they
+// share.
+func relatedByTaxonomy(posts []Page) map[Page][]Page {
+	related := make(map[Page][]Page, len(posts))
+	for _, p := range posts {
+		var (
+			tags       = utils.MakeStringSet(p.Tags())
+			categories = utils.MakeStringSet(p.Categories())
+		)
+		related[p] = rankPosts(posts, p, func(q Page) float64 {
+			n := 0
+			for _, t := range q.Tags() {
+				if tags[t] {
+					n++
+				}
+			}
+			for _, c := range q.Categories() {
+				if categories[c] {
+					n++
+				}
+			}
+			return float64(n)
+		})
+	}
+	return related
+}
+
+// relatedByLSI ranks posts by the similarity of their content. The result is
+// cached across builds, keyed by the posts' paths and content.
+func (s *Site) relatedByLSI(posts []Page) (map[Page][]Page, error) {
+	var (
+		paths   = make([]string, len(posts))
+		texts   = make([]string, len(posts))
+		bySrc   = make(map[string]Page, len(posts))
+		content strings.Builder
+	)
+	for i, p := range posts {
+		text, err := postText(p.Source())
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = utils.MustRel(s.SourceDir(), p.Source())
+		texts[i] = text
+		bySrc[paths[i]] = p
+		content.WriteString(paths[i])
+		content.WriteByte(0)
+		content.WriteString(text)
+		content.WriteByte(0)
+	}
+	// The cached value is a line per post: its path, then the paths of its
+	// related posts, separated by tabs.
+	cached, err := cache.WithFile("related posts (lsi)", content.String(), func() (string, error) {
+		var (
+			vectors = tfidf(texts)
+			lines   = make([]string, len(posts))
+			index   = make(map[Page]int, len(posts))
+		)
+		for i, p := range posts {
+			index[p] = i
+		}
+		for i, p := range posts {
+			ranked := rankPosts(posts, p, func(q Page) float64 {
+				return vectors[i].dot(vectors[index[q]])
+			})
+			fields := []string{paths[i]}
+			for _, q := range ranked {
+				fields = append(fields, utils.MustRel(s.SourceDir(), q.Source()))
+			}
+			lines[i] = strings.Join(fields, "\t")
+		}
+		return strings.Join(lines, "\n") + "\n", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	related := make(map[Page][]Page, len(posts))
+	for _, line := range strings.Split(strings.TrimSuffix(cached, "\n"), "\n") {
+		fields := strings.Split(line, "\t")
+		p := bySrc[fields[0]]
+		if p == nil {
+			continue
+		}
+		ps := []Page{}
+		for _, rel := range fields[1:] {
+			if q := bySrc[rel]; q != nil {
+				ps = append(ps, q)
+			}
+		}
+		related[p] = ps
+	}
+	return related, nil
+}
+
+// rankPosts returns up to maxRelatedPosts posts other than p, in decreasing
+// order of score. posts are in date order, and the sort is stable, so ties
+// go to the more recent post.
+func rankPosts(posts []Page, p Page, score func(Page) float64) []Page {
+	var (
+		ranked = make([]Page, 0, len(posts))
+		scores = make(map[Page]float64, len(posts))
+	)
+	for _, q := range posts {
+		if q != p {
+			ranked = append(ranked, q)
+			scores[q] = score(q)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i]] > scores[ranked[j]]
+	})
+	if len(ranked) > maxRelatedPosts {
+		ranked = ranked[:maxRelatedPosts]
+	}
+	return ranked
+}
+
+// postText returns the content of the file at filename, without its front
+// matter.
+func postText(filename string) (string, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return "", utils.WrapPathError(err, filename)
+	}
+	if _, err := frontmatter.Read(&b, nil); err != nil {
+		return "", utils.WrapPathError(err, filename)
+	}
+	return string(b), nil
+}
+
+// A termVector maps terms to weights. tfidf normalizes these to unit length,
+// so that their dot product is their cosine similarity.
+type termVector map[string]float64
+
+func (v termVector) dot(w termVector) float64 {
+	if len(w) < len(v) {
+		v, w = w, v
+	}
+	sum := 0.0
+	for t, x := range v {
+		sum += x * w[t]
+	}
+	return sum
+}
+
+// tfidf returns the normalized TF-IDF vectors of texts.
+func tfidf(texts []string) []termVector {
+	var (
+		counts = make([]map[string]int, len(texts))
+		df     = map[string]int{}
+	)
+	for i, text := range texts {
+		counts[i] = map[string]int{}
+		for _, t := range terms(text) {
+			if counts[i][t] == 0 {
+				df[t]++
+			}
+			counts[i][t]++
+		}
+	}
+	vectors := make([]termVector, len(texts))
+	for i, tc := range counts {
+		var (
+			v    = termVector{}
+			norm = 0.0
+		)
+		for t, n := range tc {
+			w := float64(n) * math.Log(float64(len(texts))/float64(df[t]))
+			if w > 0 {
+				v[t] = w
+				norm += w * w
+			}
+		}
+		norm = math.Sqrt(norm)
+		for t := range v {
+			v[t] /= norm
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+// terms splits text into lowercase words. It skips words shorter than three
+// letters, which are mostly stop words.
+func terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) >= 3 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package site
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRelatedPostsSite(t *testing.T) string {
+	return writeSiteFiles(t, map[string]string{
+		"_layouts/post.html":           "{% for p in site.related_posts %}[{{ p.title }}]{% endfor %}",
+		"_posts/2020-01-01-go.md":      "---\nlayout: post\ntitle: go\ntags: [go, code]\n---\ngoroutines channels interfaces",
+		"_posts/2020-01-02-cats.md":    "---\nlayout: post\ntitle: cats\ntags: [pets]\n---\nwhiskers kittens purring",
+		"_posts/2020-01-03-rust.md":    "---\nlayout: post\ntitle: rust\ntags: [code]\n---\nborrow checker lifetimes traits",
+		"_posts/2020-01-04-gophers.md": "---\nlayout: post\ntitle: gophers\n---\ngoroutines channels gophers",
+	})
+}
+
+func renderRelatedPosts(t *testing.T, dir string, flags config.Flags, url string) string {
+	s, err := FromDirectory(dir, flags)
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	p, found := s.Routes[url]
+	require.True(t, found, url)
+	buf := new(bytes.Buffer)
+	require.NoError(t, s.WriteDocument(buf, p))
+	return buf.String()
+}
+
+func TestSite_RelatedPosts(t *testing.T) {
+	dir := writeRelatedPostsSite(t)
+	out := renderRelatedPosts(t, dir, config.Flags{}, "/2020/01/01/go.html")
+	// rust shares a tag; the others are by date
+	require.Equal(t, "[rust][gophers][cats]", out)
+
+	out = renderRelatedPosts(t, dir, config.Flags{}, "/2020/01/02/cats.html")
+	require.Equal(t, "[gophers][rust][go]", out)
+}
+
+func TestSite_RelatedPosts_lsi(t *testing.T) {
+	cache.Disable()
+	defer cache.Enable()
+	var (
+		dir = writeRelatedPostsSite(t)
+		lsi = true
+	)
+	out := renderRelatedPosts(t, dir, config.Flags{LSI: &lsi}, "/2020/01/01/go.html")
+	// gophers shares words; the others are by date
+	require.Equal(t, "[gophers][rust][cats]", out)
+}
+
+func TestSite_RelatedPosts_rebuild(t *testing.T) {
+	dir := writeRelatedPostsSite(t)
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	s.cfg.Incremental = true
+	require.NoError(t, s.Read())
+	_, err = s.Write()
+	require.NoError(t, err)
+	titles := func() (titles []string) {
+		for _, p := range s.RelatedPosts(s.Routes["/2020/01/01/go.html"].(Page)) {
+			titles = append(titles, p.FrontMatter()["title"].(string))
+		}
+		return
+	}
+	require.Equal(t, []string{"rust", "gophers", "cats"}, titles())
+
+	// cats now shares both tags
+	rel := filepath.Join("_posts", "2020-01-02-cats.md")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, rel), []byte("---\nlayout: post\ntitle: cats\ntags: [go, code]\n---\n"), 0644))
+	r, _, err := s.rebuild(FilesEvent{Paths: []string{rel}, Ops: map[string]FileOp{}})
+	require.NoError(t, err)
+	require.Equal(t, s, r)
+	require.Equal(t, []string{"cats", "rust", "gophers"}, titles())
+}
+
+func TestTfidf(t *testing.T) {
+	vs := tfidf([]string{"apple banana", "apple cherry", "durian"})
+	require.InDelta(t, 1.0, vs[0].dot(vs[0]), 1e-9)
+	require.Greater(t, vs[0].dot(vs[1]), 0.0)
+	require.Equal(t, 0.0, vs[0].dot(vs[2]))
+}
@@ -2,6 +2,7 @@ package site
 
 import (
 	"sort"
+	"sync"
 
 	"github.com/osteele/gojekyll/collection"
 )
@@ -35,6 +36,15 @@ func (s *Site) ensureRendered() (err error) {
 	return
 }
 
+// resetCaches forgets the rendered pages, the site drop, and the related
+// posts, so that they reflect changes to the site's documents.
+func (s *Site) resetCaches() {
+	s.renderOnce = sync.Once{}
+	s.drop = nil
+	s.dropOnce = sync.Once{}
+	s.resetRelatedPosts()
+}
+
 // returns a slice of collections, sorted by name but with _posts last.
 func (s *Site) sortedCollections() []*collection.Collection {
 	cols := make([]*collection.Collection, len(s.Collections))
@@ -2,10 +2,13 @@ package site
 
 import (
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/osteele/gojekyll/collection"
 	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/frontmatter"
 	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/plugins"
 	"github.com/osteele/gojekyll/renderers"
@@ -18,20 +21,29 @@ type Site struct {
 	Collections []*collection.Collection
 	Routes      map[string]Document // URL path -> Document; only for output pages
 
-	cfg      config.Config
-	data     map[string]interface{} // from _data files
-	flags    config.Flags           // command-line flags, override config files
-	plugins  []string               // initially cfg.Plugins, but plugins can modify this this
-	themeDir string                 // absolute path to theme directory
+	cfg         config.Config
+	data        map[string]interface{} // from _data files
+	dataSources map[string]string      // site.data path -> data file
+	flags       config.Flags           // command-line flags, override config files
+	plugins     []string               // initially cfg.Plugins, but plugins can modify this this
+	themeDirs   []string               // absolute paths to the theme directory and its ancestors
+	watcher     *fileWatcher           // nil unless WatchFiles was called
+	languages   map[string]*Site       // language -> site; only for a localized site
+	readTime    time.Time              // when Read began
 
 	docs               []Document // all documents, whether or not they are output
 	nonCollectionPages []Page
+	pagesSchema        *frontmatter.Schema // the front matter schema of nonCollectionPages
+	schemaErrors       []error             // the pages that don't match their schema
 
 	renderer   *renderers.Manager
 	renderOnce sync.Once
 
 	drop     map[string]interface{} // cached drop value
 	dropOnce sync.Once
+
+	related     map[Page][]Page // post -> related posts
+	relatedOnce sync.Once
 }
 
 // Document is in package pages.
@@ -117,6 +129,9 @@ func (s *Site) SetAbsoluteURL(url string) {
 	if s.drop != nil {
 		s.drop["url"] = url
 	}
+	for _, l := range s.languages {
+		l.SetAbsoluteURL(url)
+	}
 }
 
 // FilenameURLs returns a map of site-relative pathnames to URL paths
@@ -156,6 +171,25 @@ func (s *Site) FilenameURLPath(relpath string) (string, bool) {
 	return "", false
 }
 
+// linkURL is the link and post_url tags' file lookup. A path in _posts also
+// matches a post in a category directory, so that {% post_url 2017-01-02-x %}
+// finds blog/_posts/2017-01-02-x.md.
+func (s *Site) linkURL(relpath string) (string, bool) {
+	if url, found := s.FilenameURLPath(relpath); found {
+		return url, true
+	}
+	if !strings.HasPrefix(relpath, "_posts/") {
+		return "", false
+	}
+	suffix := "/" + relpath
+	for _, p := range s.Posts() {
+		if strings.HasSuffix(filepath.ToSlash(p.Source()), suffix) {
+			return p.URL(), true
+		}
+	}
+	return "", false
+}
+
 // RendererManager returns the rendering manager.
 func (s *Site) RendererManager() renderers.Renderers {
 	if s.renderer == nil {
@@ -172,8 +206,8 @@ func (s *Site) TemplateEngine() *liquid.Engine {
 // initializeRenderers initializes the rendering manager
 func (s *Site) initializeRenderers() (err error) {
 	options := renderers.Options{
-		RelativeFilenameToURL: s.FilenameURLPath,
-		ThemeDir:              s.themeDir,
+		RelativeFilenameToURL: s.linkURL,
+		ThemeDirs:             s.themeDirs,
 	}
 	s.renderer, err = renderers.New(s.cfg, options)
 	if err != nil {
@@ -193,10 +227,8 @@ func (s *Site) HasLayout(ln string) bool {
 
 // RelativePath is in the page.Container interface.
 func (s *Site) RelativePath(path string) string {
-	if s.themeDir != "" {
-		if rel, err := filepath.Rel(s.themeDir, path); err == nil {
-			return rel
-		}
+	if dir := s.themeDirFor(path); dir != "" {
+		return utils.MustRel(dir, path)
 	}
 	return utils.MustRel(s.cfg.Source, path)
 }
@@ -1,12 +1,27 @@
 package site
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/stretchr/testify/require"
 )
 
+// writeSiteFiles writes files, a map from site-relative paths to contents, to
+// a new temporary directory, and returns the directory.
+func writeSiteFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	}
+	return dir
+}
+
 func TestKeepFile(t *testing.T) {
 	s := New(config.Flags{})
 	require.Equal(t, "", s.PathPrefix())
@@ -62,3 +77,108 @@ func TestIsIncludedPath(t *testing.T) {
 	require.False(t, s.isIncludedPath("pages"))
 	require.False(t, s.isIncludedPath("pages/about.md"))
 }
+
+func TestPostURLCategoryDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "blog", "_posts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blog", "_posts", "2017-08-02-nested.md"), []byte("---\n---\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.md"), []byte("---\n---\n{% post_url 2017-08-02-nested %}\n"), 0644))
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	require.Contains(t, s.Routes, "/blog/2017/08/02/nested.html")
+
+	p, found := s.Routes["/index.html"]
+	require.True(t, found)
+	buf := new(bytes.Buffer)
+	require.NoError(t, s.WriteDocument(buf, p))
+	require.Contains(t, buf.String(), "/blog/2017/08/02/nested.html")
+}
+
+func TestSite_readFiles_collectionStaticFiles(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml":              "collections:\n  docs:\n    output: true\n",
+		"_docs/guide.md":           "---\n---\n",
+		"_docs/images/diagram.png": "png",
+		"_notes/todo.txt":          "todo",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+
+	// ReadCollections reads the collection's static files, not readFiles
+	var diagrams []string
+	for _, f := range s.staticFiles() {
+		if filepath.Base(f.Source()) == "diagram.png" {
+			diagrams = append(diagrams, f.URL())
+		}
+	}
+	require.Equal(t, []string{"/docs/images/diagram.png"}, diagrams)
+	require.NotContains(t, s.Routes, "/_docs/images/diagram.png")
+
+	// other top-level underscore directories are read as before
+	require.Contains(t, s.Routes, "/_notes/todo.txt")
+}
+
+func TestFromDirectory_expandEnv(t *testing.T) {
+	t.Setenv("GOJEKYLL_TEST_URL", "https://staging.example.com")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_config.yml"), []byte("url: ${GOJEKYLL_TEST_URL}\n"), 0644))
+
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.Equal(t, "https://staging.example.com", s.cfg.AbsoluteURL)
+
+	safe := true
+	_, err = FromDirectory(dir, config.Flags{Safe: &safe})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "GOJEKYLL_TEST_URL")
+
+	s, err = FromDirectory(dir, config.Flags{Safe: &safe, EnvAllowlist: []string{"GOJEKYLL_TEST_URL"}})
+	require.NoError(t, err)
+	require.Equal(t, "https://staging.example.com", s.cfg.AbsoluteURL)
+	require.True(t, s.cfg.Safe)
+}
+
+func TestSite_FrontMatterErrors(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml": `collections:
+  docs:
+    output: true
+    schema:
+      required: [title, section, order]
+      properties:
+        section: {enum: [intro, guide]}
+        order: {type: integer}
+  recipes:
+    output: true
+defaults:
+  - scope: {type: docs}
+    values: {section: intro}
+`,
+		"_schemas/recipes.yml": "required: [serves]\n",
+		"_schemas/pages.yml":   "properties: {title: {type: string}}\n",
+		"_docs/ok.md":          "---\ntitle: OK\nsection: intro\norder: 1\n---\n",
+		"_docs/bad.md":         "---\ntitle: Bad\nsection: misc\norder: first\n---\n",
+		// The defaults supply its section.
+		"_docs/defaulted.md":   "---\ntitle: Defaulted\norder: 2\n---\n",
+		"_recipes/soup.md":     "---\ntitle: Soup\n---\n",
+		"_recipes/stew.md":     "---\ntitle: [Stew]\nserves: 2\n---\n",
+		"about.md":             "---\ntitle: [a]\n---\n",
+		"plain.md":             "no front matter\n",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	err = s.FrontMatterErrors()
+	require.Error(t, err)
+	require.Equal(t, filepath.Join(dir, "_docs/bad.md")+`: order: expected integer, not the string "first"`+"\n"+
+		filepath.Join(dir, "_docs/bad.md")+`: section: must be one of intro, guide, not the string "misc"`+"\n"+
+		filepath.Join(dir, "_recipes/soup.md")+": missing the required variable serves"+"\n"+
+		filepath.Join(dir, "about.md")+": title: expected string, not a list",
+		err.Error())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_schemas/pages.yml"), []byte("type: [1]\n"), 0644))
+	require.Error(t, s.Read())
+	require.True(t, s.RequiresFullReload([]string{"_schemas/pages.yml"}))
+}
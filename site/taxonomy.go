@@ -0,0 +1,118 @@
+package site
+
+import (
+	"sort"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/utils"
+)
+
+// taxonomies returns the value of site.taxonomies. This maps each taxonomy
+// name to a map from term slugs to terms. site.taxonomies.tags and
+// site.taxonomies.categories are always present.
+//
+// A term is a map with the term's name and slug, the documents that have
+// it, newest first, and their count:
+//
+//	{% for item in site.taxonomies.tags %}{{ item[1].name }} ({{ item[1].count }}){% endfor %}
+func (s *Site) taxonomies() map[string]interface{} {
+	taxonomies := map[string]config.Taxonomy{"tags": {}, "categories": {}}
+	for name, t := range s.cfg.Taxonomies {
+		taxonomies[name] = t
+	}
+	drop := make(map[string]interface{}, len(taxonomies))
+	for name, t := range taxonomies {
+		var ps []Page
+		for _, cn := range t.CollectionNames() {
+			if c := s.findCollection(cn); c != nil {
+				ps = append(ps, c.Pages()...)
+			}
+		}
+		drop[name] = taxonomyTerms(groupPagesBy(ps, taxonomyGetter(t.FieldName(name))))
+	}
+	return drop
+}
+
+// taxonomyGetter returns a function that returns a page's values of a front
+// matter field. The tags and categories fields use the Page methods, which
+// also split space-separated strings, and add the categories that a post's
+// directory implies.
+func taxonomyGetter(field string) func(Page) []string {
+	switch field {
+	case "tags":
+		return Page.Tags
+	case "categories":
+		return Page.Categories
+	default:
+		return func(p Page) []string { return p.FrontMatter().StringArray(field) }
+	}
+}
+
+// taxonomyTerms merges the groups whose names have the same slug, such as
+// "Go" and "go", into terms keyed by their slugs.
+func taxonomyTerms(groups map[string][]Page) map[string]interface{} {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	terms := map[string]interface{}{}
+	for _, name := range names {
+		slug := utils.Slugify(name)
+		if slug == "" {
+			continue
+		}
+		ps := groups[name]
+		if t, ok := terms[slug].(map[string]interface{}); ok {
+			// a merged term keeps its first name
+			name = t["name"].(string)
+			ps = mergePages(t["posts"].([]Page), ps)
+		}
+		terms[slug] = map[string]interface{}{
+			"name":  name,
+			"slug":  slug,
+			"posts": ps,
+			"count": len(ps),
+		}
+	}
+	return terms
+}
+
+// groupPagesBy groups pages by the values that getter returns. Each group is
+// sorted by date, newest first.
+func groupPagesBy(ps []Page, getter func(Page) []string) map[string][]Page {
+	groups := map[string][]Page{}
+	for _, p := range ps {
+		for _, k := range getter(p) {
+			groups[k] = append(groups[k], p)
+		}
+	}
+	for _, g := range groups {
+		sortPagesByDate(g)
+	}
+	return groups
+}
+
+// mergePages returns the union of a and b, sorted by date, newest first.
+func mergePages(a, b []Page) []Page {
+	seen := map[Page]bool{}
+	out := make([]Page, 0, len(a)+len(b))
+	for _, p := range append(append([]Page{}, a...), b...) {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sortPagesByDate(out)
+	return out
+}
+
+func sortPagesByDate(ps []Page) {
+	sort.SliceStable(ps, func(i, j int) bool {
+		a, b := ps[i].PostDate(), ps[j].PostDate()
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return ps[i].Source() < ps[j].Source()
+	})
+}
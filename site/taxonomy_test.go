@@ -0,0 +1,52 @@
+package site
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_taxonomies(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml":            "collections:\n  books:\n    output: true\ntaxonomies:\n  authors:\n    field: author\n    collections: [posts, books]\n",
+		"_posts/2020-01-01-a.md": "---\ntags: [Go, code]\ncategories: news\nauthor: Ada Lovelace\n---\n",
+		"_posts/2020-01-03-b.md": "---\ntags: [go]\n---\n",
+		"_posts/2020-01-02-c.md": "---\ntags: code\nauthor: Alan Turing\n---\n",
+		"_books/engines.md":      "---\nauthor: Ada Lovelace\n---\n",
+		"pages/not-a-post.md":    "---\ntags: [code]\n---\n",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	s.ToLiquid()
+	drop := s.drop
+	title := func(p Page) string { return filepath.Base(p.Source()) }
+	titles := func(ps []Page) (out []string) {
+		for _, p := range ps {
+			out = append(out, title(p))
+		}
+		return
+	}
+
+	// the legacy variables group posts by name, newest first
+	tags := drop["tags"].(map[string][]Page)
+	require.Equal(t, []string{"2020-01-02-c.md", "2020-01-01-a.md"}, titles(tags["code"]))
+	require.Equal(t, []string{"2020-01-01-a.md"}, titles(tags["Go"]))
+	categories := drop["categories"].(map[string][]Page)
+	require.Equal(t, []string{"2020-01-01-a.md"}, titles(categories["news"]))
+	require.NotContains(t, categories, "code")
+
+	taxonomies := drop["taxonomies"].(map[string]interface{})
+	goTerm := taxonomies["tags"].(map[string]interface{})["go"].(map[string]interface{})
+	require.Equal(t, "Go", goTerm["name"])
+	require.Equal(t, 2, goTerm["count"])
+	require.Equal(t, []string{"2020-01-03-b.md", "2020-01-01-a.md"}, titles(goTerm["posts"].([]Page)))
+
+	authors := taxonomies["authors"].(map[string]interface{})
+	require.Len(t, authors, 2)
+	ada := authors["ada-lovelace"].(map[string]interface{})
+	require.Equal(t, "Ada Lovelace", ada["name"])
+	require.Equal(t, 2, ada["count"])
+}
@@ -1,47 +1,139 @@
 package site
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
 )
 
+// A theme can declare a parent theme, with a theme setting in its own
+// _config.yml. The site's layouts, includes, Sass partials, assets, data and
+// configuration override those of its theme, which override those of the
+// theme's parent, and so on.
+
+// findTheme sets s.themeDirs to the directory of the theme that the theme
+// setting names, followed by the directories of its ancestors.
 func (s *Site) findTheme() error {
+	s.themeDirs = nil
 	if s.cfg.Theme == "" {
 		return nil
 	}
+	dir, err := s.resolveTheme(s.cfg.Theme, s.AbsDir())
+	if err != nil {
+		return err
+	}
+	s.themeDirs = []string{dir}
+	for {
+		parent, err := themeParent(dir)
+		if err != nil || parent == "" {
+			return err
+		}
+		if dir, err = s.resolveTheme(parent, dir); err != nil {
+			return err
+		}
+		if utils.StringArrayContains(s.themeDirs, dir) {
+			return fmt.Errorf("the %s theme inherits from itself", parent)
+		}
+		s.themeDirs = append(s.themeDirs, dir)
+	}
+}
+
+// themeParent returns the theme setting in the _config.yml in the theme
+// directory dir, or "" if it doesn't have one.
+func themeParent(dir string) (string, error) {
+	filename := filepath.Join(dir, "_config.yml")
+	b, err := os.ReadFile(filename)
+	switch {
+	case os.IsNotExist(err):
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+	var cfg struct{ Theme string }
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return "", utils.WrapPathError(err, filename)
+	}
+	return cfg.Theme, nil
+}
 
+// resolveTheme returns the directory of a theme. The theme is a directory
+// in _theme; a local directory or a .gem, .tar.gz or .tgz archive, relative
+// to dir; or an installed gem, whose version is the one in Gemfile.lock if
+// the site has one.
+func (s *Site) resolveTheme(theme, dir string) (string, error) {
 	// First, try to find theme in _theme folder
-	themeDir := filepath.Join(s.AbsDir(), "_theme", s.cfg.Theme)
+	themeDir := filepath.Join(s.AbsDir(), "_theme", theme)
 	if _, err := os.Stat(themeDir); err == nil {
-		s.themeDir = themeDir
-		return nil
+		return themeDir, nil
 	}
 
-	// Fallback to using bundle
-	exe, err := exec.LookPath("bundle")
-	if err != nil {
-		return fmt.Errorf("the %s theme could not be found in _theme folder and bundle is not available", s.cfg.Theme)
+	// Next, a path to a directory or archive
+	path := theme
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	switch {
+	case isThemeArchive(theme):
+		return unpackTheme(path)
+	case strings.ContainsAny(theme, `/\`) || strings.HasPrefix(theme, "."):
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("the %s theme directory could not be found", theme)
+		}
+		return path, nil
 	}
-	cmd := exec.Command(exe, "show", s.cfg.Theme)
-	cmd.Dir = s.AbsDir()
-	out, err := cmd.CombinedOutput()
+
+	// Finally, an installed gem
+	gems, err := readGemfileLock(s.AbsDir())
 	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("the %s theme could not be found", s.cfg.Theme)
+		return "", err
+	}
+	locked := gems[theme]
+	if locked.path != "" {
+		return locked.path, nil
+	}
+	if dir, found := findGem(gemDirs(s.AbsDir()), theme, locked.version); found {
+		return dir, nil
+	}
+	if locked.version != "" {
+		return "", fmt.Errorf("the %s theme version %s (from Gemfile.lock) is not installed", theme, locked.version)
+	}
+	return "", fmt.Errorf("the %s theme could not be found in the _theme folder or the installed gems", theme)
+}
+
+// readThemeAssets reads the assets of the themes, ancestors first, so that
+// a theme's assets replace those of its parent.
+func (s *Site) readThemeAssets() error {
+	for i := len(s.themeDirs) - 1; i >= 0; i-- {
+		dir := s.themeDirs[i]
+		err := s.readFiles(filepath.Join(dir, "assets"), dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
 		}
-		return err
 	}
-	s.themeDir = string(bytes.TrimSpace(out))
 	return nil
 }
 
-func (s *Site) readThemeAssets() error {
-	err := s.readFiles(filepath.Join(s.themeDir, "assets"), s.themeDir)
-	if os.IsNotExist(err) {
-		return nil
+// readThemeConfig merges the themes' _config.yml files beneath the site
+// configuration, each beneath that of the theme that inherits from it.
+func (s *Site) readThemeConfig() error {
+	for _, dir := range s.themeDirs {
+		if err := s.cfg.MergeThemeConfig(filepath.Join(dir, "_config.yml")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// themeDirFor returns the theme directory that contains filename, or "".
+func (s *Site) themeDirFor(filename string) string {
+	for _, dir := range s.themeDirs {
+		if rel, err := filepath.Rel(dir, filename); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return dir
+		}
 	}
-	return err
+	return ""
 }
@@ -0,0 +1,250 @@
+package site
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/utils"
+)
+
+// A gem-based theme is found without Ruby: its version comes from the
+// site's Gemfile.lock, if there is one, and the gem from the directories
+// where RubyGems and Bundler install gems.
+
+// lockedGem describes a gem in Gemfile.lock. path is set for a gem from a
+// PATH source, which Bundler uses in place.
+type lockedGem struct {
+	version string
+	path    string
+}
+
+var lockfileSpecMatcher = regexp.MustCompile(`^    (\S+) \(([^)]+)\)$`)
+
+// readGemfileLock returns the gems in the Gemfile.lock in dir. It returns an
+// empty map if there isn't a Gemfile.lock.
+func readGemfileLock(dir string) (map[string]lockedGem, error) {
+	gems := map[string]lockedGem{}
+	f, err := os.Open(filepath.Join(dir, "Gemfile.lock"))
+	switch {
+	case os.IsNotExist(err):
+		return gems, nil
+	case err != nil:
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+	var (
+		scanner = bufio.NewScanner(f)
+		section string // GEM, PATH, GIT, PLATFORMS, …
+		remote  string // the source of a PATH section
+	)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line != "" && !strings.HasPrefix(line, " "):
+			section, remote = line, ""
+		case section == "PATH" && strings.HasPrefix(line, "  remote: "):
+			remote = strings.TrimPrefix(line, "  remote: ")
+			if !filepath.IsAbs(remote) {
+				remote = filepath.Join(dir, remote)
+			}
+		case section == "GEM" || section == "PATH" || section == "GIT":
+			if m := lockfileSpecMatcher.FindStringSubmatch(line); m != nil {
+				gems[m[1]] = lockedGem{version: m[2], path: remote}
+			}
+		}
+	}
+	return gems, scanner.Err()
+}
+
+// gemDirs returns the directories to search for installed gems: the site's
+// vendor/bundle, GEM_HOME, GEM_PATH, BUNDLE_PATH, and the default
+// directories of RubyGems on Linux and macOS. Each has a gems subdirectory.
+func gemDirs(siteDir string) []string {
+	var (
+		dirs     []string
+		patterns = []string{filepath.Join(siteDir, "vendor", "bundle", "ruby", "*")}
+	)
+	if s := os.Getenv("BUNDLE_PATH"); s != "" {
+		if !filepath.IsAbs(s) {
+			s = filepath.Join(siteDir, s)
+		}
+		patterns = append(patterns, filepath.Join(s, "ruby", "*"))
+	}
+	if s := os.Getenv("GEM_HOME"); s != "" {
+		dirs = append(dirs, s)
+	}
+	if s := os.Getenv("GEM_PATH"); s != "" {
+		dirs = append(dirs, filepath.SplitList(s)...)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		patterns = append(patterns,
+			filepath.Join(home, ".gem", "ruby", "*"),
+			filepath.Join(home, ".local", "share", "gem", "ruby", "*"))
+	}
+	patterns = append(patterns, defaultGemDirPatterns...)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err == nil {
+			dirs = append(dirs, matches...)
+		}
+	}
+	return dirs
+}
+
+// defaultGemDirPatterns are where a system or Homebrew Ruby installs gems.
+// Tests replace them.
+var defaultGemDirPatterns = []string{
+	"/usr/local/lib/ruby/gems/*",
+	"/usr/lib/ruby/gems/*",
+	"/var/lib/gems/*",
+	"/opt/homebrew/lib/ruby/gems/*",
+	"/Library/Ruby/Gems/*",
+}
+
+// findGem returns the directory of the installed gem name. If version is
+// empty, it returns the latest version that it finds.
+func findGem(dirs []string, name, version string) (string, bool) {
+	var (
+		found    string
+		foundVer string
+	)
+	for _, dir := range dirs {
+		if version != "" {
+			gemDir := filepath.Join(dir, "gems", name+"-"+version)
+			if info, err := os.Stat(gemDir); err == nil && info.IsDir() {
+				return gemDir, true
+			}
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "gems", name+"-*"))
+		if err != nil {
+			continue
+		}
+		for _, gemDir := range matches {
+			v := strings.TrimPrefix(filepath.Base(gemDir), name+"-")
+			if v == "" || v[0] < '0' || v[0] > '9' {
+				// another gem whose name starts with name-
+				continue
+			}
+			if found == "" || compareGemVersions(v, foundVer) > 0 {
+				found, foundVer = gemDir, v
+			}
+		}
+	}
+	return found, found != ""
+}
+
+// compareGemVersions compares dotted gem versions, numerically where both
+// segments are numbers.
+func compareGemVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// isThemeArchive returns true if the theme setting names a gem or tarball.
+func isThemeArchive(theme string) bool {
+	for _, ext := range []string{".gem", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(theme, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// unpackTheme unpacks a .gem, .tar.gz or .tgz archive into the cache
+// directory, and returns the directory of its files. It reuses an archive
+// with the same content that it has already unpacked.
+func unpackTheme(archive string) (string, error) {
+	b, err := os.ReadFile(archive)
+	if err != nil {
+		return "", err
+	}
+	var (
+		sum   = sha256.Sum256(b)
+		dir   = filepath.Join(cache.Dir(), "themes", fmt.Sprintf("%x", sum[:16]))
+		isGem = strings.HasSuffix(archive, ".gem")
+	)
+	if _, err := os.Stat(dir); err == nil {
+		return archiveRoot(dir, isGem)
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return "", err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "unpack")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	if isGem {
+		err = extractGem(bytes.NewReader(b), tmp)
+	} else {
+		err = utils.ExtractTarGz(bytes.NewReader(b), tmp)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", archive, err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		// Another process may have unpacked the same archive meanwhile.
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return "", err
+		}
+	}
+	return archiveRoot(dir, isGem)
+}
+
+// archiveRoot returns the theme directory within dir, where an archive was
+// unpacked. A gem's files are at the top level. A tarball of a theme's
+// repository usually holds a single directory, and the theme is in that.
+func archiveRoot(dir string, isGem bool) (string, error) {
+	if isGem {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+// extractGem extracts the files of a .gem, which is a tar archive whose
+// data.tar.gz member holds the gem's files.
+func extractGem(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		switch {
+		case err == io.EOF:
+			return fmt.Errorf("the gem has no data.tar.gz")
+		case err != nil:
+			return err
+		case h.Name == "data.tar.gz":
+			return utils.ExtractTarGz(tr, dir)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package site
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func TestFindTheme(t *testing.T) {
@@ -21,7 +25,7 @@ defer func() { require.NoError(t, os.RemoveAll(tempDir)) }()
 		s.cfg.Source = tempDir
 		err := s.findTheme()
 		require.NoError(t, err)
-		require.Empty(t, s.themeDir)
+		require.Empty(t, s.themeDirs)
 	})
 
 	t.Run("theme found in _theme folder", func(t *testing.T) {
@@ -37,7 +41,7 @@ defer func() { require.NoError(t, os.RemoveAll(tempDir)) }()
 		
 		err = s.findTheme()
 		require.NoError(t, err)
-		require.Equal(t, themeDir, s.themeDir)
+		require.Equal(t, []string{themeDir}, s.themeDirs)
 	})
 
 	t.Run("theme not found anywhere", func(t *testing.T) {
@@ -68,7 +72,7 @@ defer func() { require.NoError(t, os.RemoveAll(cleanTempDir)) }()
 		
 		err := s.findTheme()
 		require.NoError(t, err)
-		require.Empty(t, s.themeDir)
+		require.Empty(t, s.themeDirs)
 	})
 
 	t.Run("_theme directory exists but theme subdirectory does not", func(t *testing.T) {
@@ -101,9 +105,9 @@ defer func() { require.NoError(t, os.RemoveAll(cleanTempDir)) }()
 		
 		err = s.findTheme()
 		require.NoError(t, err)
-		require.Equal(t, themeDir, s.themeDir)
+		require.Equal(t, []string{themeDir}, s.themeDirs)
 		// Verify the path is what we expect from _theme folder
-		require.Contains(t, s.themeDir, "_theme/priority-theme")
+		require.Contains(t, s.themeDirs[0], "_theme/priority-theme")
 	})
 }
 
@@ -128,7 +132,7 @@ defer func() { require.NoError(t, os.RemoveAll(tempDir)) }()
 		flags := config.Flags{}
 		s := New(flags)
 		s.cfg.Source = tempDir
-		s.themeDir = themeDir
+		s.themeDirs = []string{themeDir}
 		s.Routes = make(map[string]Document) // Initialize Routes map
 		
 		err = s.readThemeAssets()
@@ -144,10 +148,180 @@ defer func() { require.NoError(t, os.RemoveAll(tempDir)) }()
 		flags := config.Flags{}
 		s := New(flags)
 		s.cfg.Source = tempDir
-		s.themeDir = themeDir
+		s.themeDirs = []string{themeDir}
 		s.Routes = make(map[string]Document) // Initialize Routes map
 		
 		err = s.readThemeAssets()
 		require.NoError(t, err) // Should not error when assets dir doesn't exist
 	})
-}
\ No newline at end of file
+}
+
+func TestSite_themeConfigAndData(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml":                   "theme: mytheme\ntitle: Site\nauthor:\n  name: Me\n",
+		"_data/nav.yml":                 "main: site\n",
+		"_theme/mytheme/_config.yml":    "title: Theme\nsubtitle: Theme subtitle\ndestination: elsewhere\nauthor:\n  name: Theme author\n  email: theme@example.com\n",
+		"_theme/mytheme/_data/nav.yml":  "main: theme\nfooter: theme\n",
+		"_theme/mytheme/_data/i18n.yml": "hello: Hello\n",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+
+	vars := s.cfg.Variables()
+	require.Equal(t, "Site", vars["title"])
+	require.Equal(t, "Theme subtitle", vars["subtitle"])
+	require.Equal(t, "./_site", s.cfg.Destination)
+	require.Equal(t, yaml.MapSlice{{Key: "name", Value: "Me"}, {Key: "email", Value: "theme@example.com"}}, vars["author"])
+
+	require.Equal(t, map[string]interface{}{"main": "site", "footer": "theme"}, s.data["nav"])
+	require.Equal(t, map[string]interface{}{"hello": "Hello"}, s.data["i18n"])
+
+	sources := s.VariableSources("site")
+	require.Equal(t, "_config.yml (config file)", sources["site.title"])
+	require.Equal(t, filepath.Join("_theme", "mytheme", "_config.yml")+" (theme)", sources["site.subtitle"])
+	require.Equal(t, filepath.Join("_data", "nav.yml"), sources["site.data.nav"])
+	require.Equal(t, filepath.Join("_theme", "mytheme", "_data", "i18n.yml"), sources["site.data.i18n"])
+	require.Equal(t, map[string]string{"site.data.i18n": sources["site.data.i18n"]}, s.VariableSources("site.data.i18n.hello"))
+	require.Nil(t, s.VariableSources("/"))
+}
+
+func TestFindTheme_gems(t *testing.T) {
+	defer func(patterns []string) { defaultGemDirPatterns = patterns }(defaultGemDirPatterns)
+	defaultGemDirPatterns = nil
+	gemHome := t.TempDir()
+	t.Setenv("GEM_HOME", gemHome)
+	t.Setenv("GEM_PATH", "")
+	t.Setenv("BUNDLE_PATH", "")
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"mytheme-1.9.0", "mytheme-1.10.0", "mytheme-extras-2.0.0"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(gemHome, "gems", name), 0755))
+	}
+	findTheme := func(dir, theme string) (string, error) {
+		s := New(config.Flags{})
+		s.cfg.Source = dir
+		s.cfg.Theme = theme
+		if err := s.findTheme(); err != nil {
+			return "", err
+		}
+		return s.themeDirs[0], nil
+	}
+
+	dir := t.TempDir()
+	themeDir, err := findTheme(dir, "mytheme")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(gemHome, "gems", "mytheme-1.10.0"), themeDir, "latest version")
+
+	lockfile := "GEM\n  remote: https://rubygems.org/\n  specs:\n    mytheme (1.9.0)\n      jekyll (>= 3.5)\n\nPATH\n  remote: ../local-theme\n  specs:\n    local-theme (0.1.0)\n\nDEPENDENCIES\n  mytheme (~> 1.0)\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Gemfile.lock"), []byte(lockfile), 0644))
+	themeDir, err = findTheme(dir, "mytheme")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(gemHome, "gems", "mytheme-1.9.0"), themeDir, "Gemfile.lock version")
+	themeDir, err = findTheme(dir, "local-theme")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(filepath.Dir(dir), "local-theme"), themeDir)
+
+	_, err = findTheme(dir, "missing-theme")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing-theme")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "themes", "local"), 0755))
+	themeDir, err = findTheme(dir, "./themes/local")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "themes", "local"), themeDir)
+}
+
+func TestFindTheme_archives(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir()) // the cache directory
+	dir := t.TempDir()
+	tarGz := func(files map[string]string) []byte {
+		buf := new(bytes.Buffer)
+		zw := gzip.NewWriter(buf)
+		tw := tar.NewWriter(zw)
+		for name, content := range files {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+			_, err := tw.Write([]byte(content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+	layout := map[string]string{"_layouts/default.html": "{{ content }}"}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "theme.tar.gz"),
+		tarGz(map[string]string{"mytheme-1.0/_layouts/default.html": "{{ content }}"}), 0644))
+	gem := new(bytes.Buffer)
+	tw := tar.NewWriter(gem)
+	data := tarGz(layout)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "metadata.gz", Mode: 0644, Size: 0, Typeflag: tar.TypeReg}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "data.tar.gz", Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "mytheme-1.0.gem"), gem.Bytes(), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "evil.tgz"),
+		tarGz(map[string]string{"../evil.txt": "x"}), 0644))
+
+	for _, theme := range []string{"theme.tar.gz", "mytheme-1.0.gem"} {
+		for i := 0; i < 2; i++ { // the second time, from the cache
+			s := New(config.Flags{})
+			s.cfg.Source = dir
+			s.cfg.Theme = theme
+			require.NoError(t, s.findTheme(), theme)
+			require.FileExists(t, filepath.Join(s.themeDirs[0], "_layouts", "default.html"), theme)
+		}
+	}
+
+	s := New(config.Flags{})
+	s.cfg.Source = dir
+	s.cfg.Theme = "evil.tgz"
+	require.Error(t, s.findTheme())
+}
+
+func TestSite_themeInheritance(t *testing.T) {
+	dir := writeSiteFiles(t, map[string]string{
+		"_config.yml":                       "theme: brand\n",
+		"index.md":                          "---\nlayout: page\n---\nindex",
+		"post.md":                           "---\nlayout: post\n---\npost",
+		"_theme/brand/_config.yml":          "theme: base\ntitle: Brand\n",
+		"_theme/brand/_layouts/post.html":   "brand post {% include footer.html %} {{ content }}",
+		"_theme/brand/assets/style.css":     "brand",
+		"_theme/base/_config.yml":           "title: Base\ndescription: Base description\n",
+		"_theme/base/_layouts/post.html":    "base post {{ content }}",
+		"_theme/base/_layouts/page.html":    "base page {{ site.title }} {{ site.description }} {{ content }}",
+		"_theme/base/_includes/footer.html": "base footer",
+		"_theme/base/assets/style.css":      "base",
+		"_theme/base/assets/script.js":      "base",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	require.Equal(t, []string{
+		filepath.Join(dir, "_theme", "brand"),
+		filepath.Join(dir, "_theme", "base"),
+	}, s.themeDirs)
+	render := func(url string) string {
+		d, found := s.URLPage(url)
+		require.True(t, found, url)
+		buf := new(bytes.Buffer)
+		require.NoError(t, s.WriteDocument(buf, d))
+		return buf.String()
+	}
+	require.Contains(t, render("/post.html"), "brand post base footer")
+	require.Contains(t, render("/"), "base page Brand Base description")
+	require.Equal(t, "brand", render("/assets/style.css"))
+	require.Equal(t, "base", render("/assets/script.js"))
+	layouts, err := s.RendererManager().LayoutFiles()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"post": filepath.Join(dir, "_theme", "brand", "_layouts", "post.html"),
+		"page": filepath.Join(dir, "_theme", "base", "_layouts", "page.html"),
+	}, layouts)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_theme", "base", "_config.yml"), []byte("theme: brand\n"), 0644))
+	s, err = FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	err = s.Read()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "inherits from itself")
+}
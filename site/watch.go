@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -15,8 +17,40 @@ import (
 // FilesEvent is a list of changed or added site source files, with a single
 // timestamp that approximates when they were changed.
 type FilesEvent struct {
-	Time  time.Time // A single time is used for all the changes
-	Paths []string  // relative to site source
+	Time  time.Time         // A single time is used for all the changes
+	Paths []string          // relative to site source
+	Ops   map[string]FileOp // path -> kind of change; absent means FileWrite
+}
+
+// FileOp is the kind of change that a FilesEvent reports for a file.
+type FileOp int
+
+const (
+	// FileWrite is a change to an existing file.
+	FileWrite FileOp = iota
+	// FileCreate is a new file. This includes the new path of a renamed file.
+	FileCreate
+	// FileRemove is a deleted file or directory.
+	FileRemove
+	// FileRename is the old path of a renamed file or directory.
+	FileRename
+)
+
+func (op FileOp) String() string {
+	return [...]string{"write", "create", "remove", "rename"}[op]
+}
+
+// then returns the combined effect of op followed by next.
+func (op FileOp) then(next FileOp) FileOp {
+	switch {
+	case op == FileCreate && next == FileWrite:
+		return FileCreate
+	case (op == FileRemove || op == FileRename) && next != FileRemove && next != FileRename:
+		// e.g. an editor that saves by replacing the file
+		return FileWrite
+	default:
+		return next
+	}
 }
 
 func (e FilesEvent) String() string {
@@ -25,42 +59,189 @@ func (e FilesEvent) String() string {
 	return fmt.Sprintf("%d file%s changed at %s", count, inflect, e.Time.Format("3:04:05PM"))
 }
 
-// WatchFiles returns a channel that receives FilesEvent on changes within the site directory.
+// Op returns the kind of change to path.
+func (e FilesEvent) Op(path string) FileOp {
+	return e.Ops[path]
+}
+
+// Removed returns the paths that no longer exist, because they were removed
+// or renamed.
+func (e FilesEvent) Removed() []string {
+	var paths []string
+	for _, path := range e.Paths {
+		if op := e.Op(path); op == FileRemove || op == FileRename {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// A fileChange is a single notification from a file system watcher.
+type fileChange struct {
+	Path string // see Site.watchPath
+	Op   FileOp
+}
+
+// filesEvent combines a batch of changes into a FilesEvent. It merges
+// changes to the same path, and drops paths that don't affect the build.
+func (s *Site) filesEvent(changes []fileChange) FilesEvent {
+	var (
+		ops   = map[string]FileOp{}
+		paths = make([]string, 0, len(changes))
+	)
+	for _, c := range changes {
+		if op, seen := ops[c.Path]; seen {
+			ops[c.Path] = op.then(c.Op)
+			continue
+		}
+		ops[c.Path] = c.Op
+		paths = append(paths, c.Path)
+	}
+	e := FilesEvent{Paths: s.affectsBuildFilter(paths), Ops: map[string]FileOp{}}
+	for _, path := range e.Paths {
+		if ops[path] != FileWrite {
+			e.Ops[path] = ops[path]
+		}
+	}
+	return e
+}
+
+// WatchFiles returns a channel that receives FilesEvent on changes within the
+// site directory and the theme directory. The channel buffers events until
+// they are received. Calling WatchFiles again returns the same channel.
+//
+// Reloaded hands the watcher on to the reloaded site. If the new
+// configuration changes which files to watch – for example, include,
+// exclude, or theme – the watcher restarts with the new configuration.
+//
+// WatchFiles can be called before Read, so as not to miss changes made while
+// the site is read and written; cf. WatchRebuild.
 func (s *Site) WatchFiles() (<-chan FilesEvent, error) {
-	filenames, err := s.makeFileWatcher()
-	if err != nil {
+	if s.watcher != nil {
+		return s.watcher.events, nil
+	}
+	w := &fileWatcher{changes: make(chan fileChange, 100)}
+	if err := w.rescope(s); err != nil {
 		return nil, err
 	}
 	var (
-		debounced = debounce(time.Second/2, filenames)
+		debounced = debounce(time.Second/2, w.changes)
 		filesets  = make(chan FilesEvent)
 	)
 	go func() {
 		for {
-			paths := s.affectsBuildFilter(<-debounced)
-			if len(paths) > 0 {
+			e := w.current().filesEvent(<-debounced)
+			if len(e.Paths) > 0 {
 				// Create a new timestamp. Except under pathological
 				// circumstances, it will be close enough.
-				filesets <- FilesEvent{time.Now(), paths}
+				e.Time = time.Now()
+				filesets <- e
+			}
+		}
+	}()
+	w.events = bufferEvents(filesets)
+	s.watcher = w
+	return w.events, nil
+}
+
+// bufferEvents relays events from input to output, queueing them while no
+// one is receiving.
+func bufferEvents(input <-chan FilesEvent) <-chan FilesEvent {
+	output := make(chan FilesEvent)
+	go func() {
+		var queue []FilesEvent
+		for {
+			var (
+				out  chan<- FilesEvent // nil, and therefore blocked, while the queue is empty
+				next FilesEvent
+			)
+			if len(queue) > 0 {
+				out, next = output, queue[0]
+			}
+			select {
+			case e := <-input:
+				queue = append(queue, e)
+			case out <- next:
+				queue = queue[1:]
 			}
 		}
 	}()
-	return filesets, nil
+	return output
+}
+
+// fileWatcher watches a site's source and theme directories. It outlives
+// the site that started it: Reloaded hands it on to the new site.
+type fileWatcher struct {
+	m       sync.Mutex
+	site    *Site
+	scope   string // the watchScope of the running watcher
+	stop    func() // stops the running watcher
+	changes chan fileChange
+	events  <-chan FilesEvent
+}
+
+func (w *fileWatcher) current() *Site {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.site
+}
+
+// rescope switches the watcher to s. It restarts the underlying file system
+// watcher if s watches a different set of files.
+func (w *fileWatcher) rescope(s *Site) error {
+	w.m.Lock()
+	defer w.m.Unlock()
+	w.site = s
+	scope := s.watchScope()
+	if scope == w.scope {
+		return nil
+	}
+	// The new watcher takes its snapshot of the files now. The old one keeps
+	// running for another poll, so that it reports the changes between its
+	// last poll and that snapshot. Any change that both report is merged by
+	// filesEvent.
+	stop, err := s.makeFileWatcher(w.changes)
+	if err != nil {
+		return err
+	}
+	if w.stop != nil {
+		time.AfterFunc(2*pollInterval, w.stop)
+	}
+	w.scope, w.stop = scope, stop
+	return nil
+}
+
+// watchScope summarizes the configuration that determines which files the
+// file watcher watches.
+func (s *Site) watchScope() string {
+	return fmt.Sprintf("%s %s %q %v %q %q", s.AbsDir(), s.DestDir(), s.themeDirs,
+		s.cfg.ForcePolling, s.cfg.Include, s.cfg.Exclude)
+}
+
+// watchPath returns the path that the file watcher reports for filename: a
+// path relative to the site source if filename is inside it, else (for a
+// theme outside the site directory) the absolute path.
+func (s *Site) watchPath(filename string) string {
+	abs := utils.MustAbs(filename)
+	rel := utils.MustRel(s.AbsDir(), abs)
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return abs
+	}
+	return rel
 }
 
-func (s *Site) makeFileWatcher() (<-chan string, error) {
+func (s *Site) makeFileWatcher(changes chan<- fileChange) (func(), error) {
 	switch {
 	case s.cfg.ForcePolling:
-		return s.makePollingWatcher()
+		return s.makePollingWatcher(changes)
 	default:
-		return s.makeEventWatcher()
+		return s.makeEventWatcher(changes)
 	}
 }
 
-func (s *Site) makeEventWatcher() (<-chan string, error) {
+func (s *Site) makeEventWatcher(changes chan<- fileChange) (func(), error) {
 	var (
 		sourceDir = s.SourceDir()
-		filenames = make(chan string, 100)
 		w, err    = fsnotify.NewWatcher()
 	)
 	if err != nil {
@@ -75,44 +256,76 @@ func (s *Site) makeEventWatcher() (<-chan string, error) {
 		}
 		if info.IsDir() {
 			rel := utils.MustRel(sourceDir, path)
-			if rel != "." && s.Exclude(rel) {
+			if rel != "." && s.Exclude(rel) && !isNestedPostsPath(rel) {
 				return filepath.SkipDir
 			}
 			return w.Add(path)
 		}
 		return nil
 	})
+	for _, themeDir := range s.themeDirs {
+		if err != nil {
+			break
+		}
+		err = filepath.Walk(themeDir, func(path string, info os.FileInfo, err error) error {
+			switch {
+			case err != nil:
+				return err
+			case !info.IsDir():
+				return nil
+			case path != themeDir && strings.HasPrefix(info.Name(), "."):
+				return filepath.SkipDir
+			}
+			return w.Add(path)
+		})
+	}
 	if err != nil {
+		_ = w.Close()
 		return nil, err
 	}
 	go func() {
 		for {
 			select {
-			case event := <-w.Events:
+			case event, ok := <-w.Events:
+				if !ok {
+					return
+				}
 				// If a new directory is created, start watching it too
 				if event.Has(fsnotify.Create) {
 					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 						_ = w.Add(event.Name)
 					}
 				}
-				filenames <- utils.MustRel(sourceDir, event.Name)
-			case err := <-w.Errors:
+				changes <- fileChange{s.watchPath(event.Name), fsnotifyOp(event.Op)}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
 				fmt.Fprintln(os.Stderr, "error:", err)
 			}
 		}
 	}()
-	return filenames, nil
+	return func() { _ = w.Close() }, nil
 }
 
-func (s *Site) makePollingWatcher() (<-chan string, error) {
+// pollInterval is how often the polling watcher checks the files.
+const pollInterval = 250 * time.Millisecond
+
+func (s *Site) makePollingWatcher(changes chan<- fileChange) (func(), error) {
 	var (
 		sourceDir = utils.MustAbs(s.SourceDir())
-		filenames = make(chan string, 100)
 		w         = watcher.New()
 	)
 	if err := w.AddRecursive(sourceDir); err != nil {
 		return nil, err
 	}
+	for _, themeDir := range s.themeDirs {
+		if filepath.IsAbs(s.watchPath(themeDir)) {
+			if err := w.AddRecursive(themeDir); err != nil {
+				return nil, err
+			}
+		}
+	}
 	for _, path := range s.cfg.Exclude {
 		if err := w.Ignore(filepath.Join(sourceDir, path)); err != nil {
 			return nil, err
@@ -125,7 +338,17 @@ func (s *Site) makePollingWatcher() (<-chan string, error) {
 		for {
 			select {
 			case event := <-w.Event:
-				filenames <- utils.MustRel(sourceDir, event.Path)
+				switch event.Op {
+				case watcher.Rename, watcher.Move:
+					changes <- fileChange{s.watchPath(event.OldPath), FileRename}
+					changes <- fileChange{s.watchPath(event.Path), FileCreate}
+				case watcher.Create:
+					changes <- fileChange{s.watchPath(event.Path), FileCreate}
+				case watcher.Remove:
+					changes <- fileChange{s.watchPath(event.Path), FileRemove}
+				default:
+					changes <- fileChange{s.watchPath(event.Path), FileWrite}
+				}
 			case err := <-w.Error:
 				fmt.Fprintln(os.Stderr, "error:", err)
 			case <-w.Closed:
@@ -134,27 +357,40 @@ func (s *Site) makePollingWatcher() (<-chan string, error) {
 		}
 	}()
 	go func() {
-		if err := w.Start(time.Millisecond * 250); err != nil {
+		if err := w.Start(pollInterval); err != nil {
 			log.Fatal(err)
 		}
 	}()
-	return filenames, nil
+	return w.Close, nil
+}
+
+func fsnotifyOp(op fsnotify.Op) FileOp {
+	switch {
+	case op.Has(fsnotify.Remove):
+		return FileRemove
+	case op.Has(fsnotify.Rename):
+		return FileRename
+	case op.Has(fsnotify.Create):
+		return FileCreate
+	default:
+		return FileWrite
+	}
 }
 
 // debounce relays values from input to output, merging successive values so long as they keep changing
 // faster than interval
 // TODO consider https://github.com/ReactiveX/RxGo
-func debounce(interval time.Duration, input <-chan string) <-chan []string {
+func debounce(interval time.Duration, input <-chan fileChange) <-chan []fileChange {
 	var (
-		pending = []string{}
-		output  = make(chan []string)
+		pending = []fileChange{}
+		output  = make(chan []fileChange)
 		ticker  <-chan time.Time
 	)
 	go func() {
 		for {
 			select {
 			case value := <-input:
-				if value == "." {
+				if value.Path == "." {
 					continue
 				}
 				pending = append(pending, value)
@@ -163,7 +399,7 @@ func debounce(interval time.Duration, input <-chan string) <-chan []string {
 				ticker = nil
 				if len(pending) > 0 {
 					output <- pending
-					pending = []string{}
+					pending = []fileChange{}
 				}
 			}
 		}
@@ -23,8 +23,10 @@ func TestEventWatcher_SubdirectoryChanges(t *testing.T) {
 	s := New(config.Flags{})
 	s.cfg.Source = dir
 
-	filenames, err := s.makeEventWatcher()
+	changes := make(chan fileChange, 100)
+	stop, err := s.makeEventWatcher(changes)
 	require.NoError(t, err)
+	defer stop()
 
 	// Give the watcher time to start
 	time.Sleep(100 * time.Millisecond)
@@ -34,40 +36,171 @@ func TestEventWatcher_SubdirectoryChanges(t *testing.T) {
 
 	// We should receive a notification for the subdirectory file
 	select {
-	case rel := <-filenames:
-		require.Equal(t, filepath.Join("subdir", "test.txt"), rel)
+	case c := <-changes:
+		require.Equal(t, fileChange{filepath.Join("subdir", "test.txt"), FileWrite}, c)
 	case <-time.After(2 * time.Second):
 		t.Fatal("timed out waiting for subdirectory file change event")
 	}
 }
 
+func TestEventWatcher_ThemeChanges(t *testing.T) {
+	dir := t.TempDir()
+	layouts := filepath.Join(dir, "_theme", "minimal", "_layouts")
+	require.NoError(t, os.MkdirAll(layouts, 0755))
+	layout := filepath.Join(layouts, "default.html")
+	require.NoError(t, os.WriteFile(layout, []byte("initial"), 0644))
+
+	s := New(config.Flags{})
+	s.cfg.Source = dir
+	s.cfg.Theme = "minimal"
+	require.NoError(t, s.findTheme())
+
+	changes := make(chan fileChange, 100)
+	stop, err := s.makeEventWatcher(changes)
+	require.NoError(t, err)
+	defer stop()
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(layout, []byte("changed"), 0644))
+	select {
+	case c := <-changes:
+		rel := c.Path
+		require.Equal(t, filepath.Join("_theme", "minimal", "_layouts", "default.html"), rel)
+		require.True(t, s.RequiresFullReload([]string{rel}))
+		require.Equal(t, []string{rel}, s.affectsBuildFilter([]string{rel}))
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for theme file change event")
+	}
+}
+
+func TestFileWatcher_Rescope(t *testing.T) {
+	dir := t.TempDir()
+	s0 := New(config.Flags{})
+	s0.cfg.Source = dir
+	w := &fileWatcher{changes: make(chan fileChange, 100)}
+	require.NoError(t, w.rescope(s0))
+	defer func() { w.stop() }()
+	scope := w.scope
+
+	// the same configuration keeps the running watcher
+	s1 := New(config.Flags{})
+	s1.cfg.Source = dir
+	require.NoError(t, w.rescope(s1))
+	require.Equal(t, scope, w.scope)
+	require.Equal(t, s1, w.current())
+
+	// a different exclude list restarts it
+	s2 := New(config.Flags{})
+	s2.cfg.Source = dir
+	s2.cfg.Exclude = append(s2.cfg.Exclude, "drafts")
+	require.NoError(t, w.rescope(s2))
+	require.NotEqual(t, scope, w.scope)
+}
+
+func TestFileWatcher_RescopePolling(t *testing.T) {
+	dir := t.TempDir()
+	s0 := New(config.Flags{})
+	s0.cfg.Source = dir
+	s0.cfg.ForcePolling = true
+	w := &fileWatcher{changes: make(chan fileChange, 100)}
+	require.NoError(t, w.rescope(s0))
+	defer func() { w.stop() }()
+	time.Sleep(pollInterval / 2)
+
+	// a change just before the restart is in the new watcher's snapshot, so
+	// the old watcher reports it
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("new"), 0644))
+	s1 := New(config.Flags{})
+	s1.cfg.Source = dir
+	s1.cfg.ForcePolling = true
+	s1.cfg.Exclude = append(s1.cfg.Exclude, "drafts")
+	require.NoError(t, w.rescope(s1))
+	for {
+		select {
+		case c := <-w.changes:
+			if c.Path == "." {
+				continue
+			}
+			require.Equal(t, fileChange{"index.html", FileCreate}, c)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the change before the restart")
+		}
+		break
+	}
+	// let the old watcher stop before the directory is removed
+	time.Sleep(2 * pollInterval)
+}
+
+func TestEventWatcher_Remove(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "test.txt")
+	require.NoError(t, os.WriteFile(file, []byte("initial"), 0644))
+
+	s := New(config.Flags{})
+	s.cfg.Source = dir
+
+	changes := make(chan fileChange, 100)
+	stop, err := s.makeEventWatcher(changes)
+	require.NoError(t, err)
+	defer stop()
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, os.Remove(file))
+	select {
+	case c := <-changes:
+		require.Equal(t, fileChange{"test.txt", FileRemove}, c)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for file removal event")
+	}
+}
+
+func TestFilesEvent(t *testing.T) {
+	s := New(config.Flags{})
+	e := s.filesEvent([]fileChange{
+		{"a.md", FileCreate},
+		{"a.md", FileWrite},
+		{"b.md", FileRename},
+		{"c.md", FileCreate},
+		{"d.md", FileRemove},
+		{"d.md", FileCreate},
+		{"e.md", FileWrite},
+		{".git/index", FileWrite},
+	})
+	require.Equal(t, []string{"a.md", "b.md", "c.md", "d.md", "e.md"}, e.Paths)
+	require.Equal(t, FileCreate, e.Op("a.md"))
+	require.Equal(t, FileRename, e.Op("b.md"))
+	require.Equal(t, FileWrite, e.Op("d.md"))
+	require.Equal(t, FileWrite, e.Op("e.md"))
+	require.Equal(t, []string{"b.md"}, e.Removed())
+}
+
 func TestDebounce(t *testing.T) {
-	input := make(chan string, 10)
+	input := make(chan fileChange, 10)
 	output := debounce(50*time.Millisecond, input)
 
 	// Send several values in quick succession
-	input <- "a"
-	input <- "b"
-	input <- "c"
+	input <- fileChange{"a", FileWrite}
+	input <- fileChange{"b", FileCreate}
+	input <- fileChange{"c", FileRemove}
 
 	select {
 	case batch := <-output:
-		require.Equal(t, []string{"a", "b", "c"}, batch)
+		require.Equal(t, []fileChange{{"a", FileWrite}, {"b", FileCreate}, {"c", FileRemove}}, batch)
 	case <-time.After(time.Second):
 		t.Fatal("timed out waiting for debounced output")
 	}
 }
 
 func TestDebounce_SkipsDot(t *testing.T) {
-	input := make(chan string, 10)
+	input := make(chan fileChange, 10)
 	output := debounce(50*time.Millisecond, input)
 
-	input <- "."
-	input <- "a"
+	input <- fileChange{".", FileWrite}
+	input <- fileChange{"a", FileWrite}
 
 	select {
 	case batch := <-output:
-		require.Equal(t, []string{"a"}, batch)
+		require.Equal(t, []fileChange{{"a", FileWrite}}, batch)
 	case <-time.After(time.Second):
 		t.Fatal("timed out waiting for debounced output")
 	}
@@ -23,7 +23,19 @@ func (s *Site) Write() (int, error) {
 	if err := s.Clean(); err != nil {
 		return 0, err
 	}
-	return s.WriteFiles()
+	count, err := s.WriteFiles()
+	if err != nil {
+		return count, err
+	}
+	// The language sites write into subdirectories, which Clean has emptied.
+	for _, l := range s.languageSites() {
+		n, err := l.Write()
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
 }
 
 // WriteFiles writes output files.
@@ -62,11 +74,7 @@ func (s *Site) WriteFiles() (count int, err error) {
 // WriteDoc writes a document to the destination directory.
 func (s *Site) WriteDoc(d Document) error {
 	from := d.Source()
-	rel := d.URL()
-	if !d.IsStatic() && filepath.Ext(rel) == "" {
-		rel = filepath.Join(rel, "index.html")
-	}
-	to := filepath.Join(s.DestDir(), rel)
+	to := s.outputPath(d)
 	if s.cfg.Verbose {
 		fmt.Println("create", to, "from", d.Source())
 	}
@@ -87,6 +95,30 @@ func (s *Site) WriteDoc(d Document) error {
 	}
 }
 
+// outputPath returns the destination file for an output document.
+func (s *Site) outputPath(d Document) string {
+	rel := d.URL()
+	if !d.IsStatic() && filepath.Ext(rel) == "" {
+		rel = filepath.Join(rel, "index.html")
+	}
+	return filepath.Join(s.DestDir(), rel)
+}
+
+// removeOutput removes a document's output file.
+func (s *Site) removeOutput(d Document) error {
+	to := s.outputPath(d)
+	if s.cfg.Verbose {
+		fmt.Println("remove", to)
+	}
+	if s.cfg.DryRun {
+		return nil
+	}
+	if err := os.Remove(to); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // WriteDocument writes the rendered document.
 func (s *Site) WriteDocument(w io.Writer, d Document) error {
 	switch p := d.(type) {
@@ -116,6 +148,9 @@ func (s *Site) WritePage(w io.Writer, p Page) error {
 	if err != nil {
 		return err
 	}
+	if s.cfg.ActiveLang != "" {
+		b = s.localizeLinks(b)
+	}
 	_, err = w.Write(b)
 	return err
 }
@@ -1,8 +1,14 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 )
 
 // A WrappedError decorates an error with a message
@@ -74,3 +80,62 @@ func WrapPathError(err error, path string) error {
 		return &pathError{path: path, cause: err}
 	}
 }
+
+type pathLineError struct {
+	pathError
+	line int
+}
+
+func (pe *pathLineError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", pe.path, pe.line, pe.cause)
+}
+
+// Line returns the 1-based line number of the error.
+func (pe *pathLineError) Line() int {
+	return pe.line
+}
+
+// WrapPathLineError returns an error that will print with a path and line
+// number. If line is not positive, it is like WrapPathError.
+func WrapPathLineError(err error, path string, line int) error {
+	if err == nil || line <= 0 {
+		return WrapPathError(err, path)
+	}
+	return &pathLineError{pathError{path: path, cause: err}, line}
+}
+
+var yamlErrorLineMatcher = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// WrapParseError returns an error that will print with a path, and with the
+// line number of a parse error from the CSV, JSON or YAML decoders. source is
+// the text that the decoder read.
+func WrapParseError(err error, path string, source []byte) error {
+	var (
+		csvErr  *csv.ParseError
+		jsonErr *json.SyntaxError
+		typeErr *json.UnmarshalTypeError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &csvErr):
+		return WrapPathLineError(csvErr.Err, path, csvErr.Line)
+	case errors.As(err, &jsonErr):
+		return WrapPathLineError(err, path, offsetLine(source, jsonErr.Offset))
+	case errors.As(err, &typeErr):
+		return WrapPathLineError(err, path, offsetLine(source, typeErr.Offset))
+	}
+	if m := yamlErrorLineMatcher.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return WrapPathLineError(errors.New("yaml: "+err.Error()[len(m[0]):]), path, line)
+	}
+	return WrapPathError(err, path)
+}
+
+// offsetLine returns the 1-based line number of a byte offset.
+func offsetLine(source []byte, offset int64) int {
+	if offset < 0 || offset > int64(len(source)) {
+		return 0
+	}
+	return 1 + bytes.Count(source[:offset], []byte("\n"))
+}
@@ -47,6 +47,34 @@ func MatchList(patterns []string, name string) bool {
 	return false
 }
 
+// MatchGlob reports whether the slash-separated path name matches pattern.
+// It is like path.Match, except that a "**" component matches any number of
+// directories, including none. A malformed pattern matches nothing.
+func MatchGlob(pattern, name string) bool {
+	return matchGlobParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
 // MustAbs is like filepath.Abs, but panics instead of returning an error.
 func MustAbs(path string) string {
 	abs, err := filepath.Abs(path)
@@ -44,3 +44,13 @@ func TestURLPathClean(t *testing.T) {
 	require.Equal(t, "/b", URLPathClean("/a/../b"))
 	require.Equal(t, "/", URLPathClean("/"))
 }
+
+func TestMatchGlob(t *testing.T) {
+	require.True(t, MatchGlob("docs/*.md", "docs/a.md"))
+	require.False(t, MatchGlob("docs/*.md", "docs/sub/a.md"))
+	require.True(t, MatchGlob("docs/**/*.md", "docs/a.md"))
+	require.True(t, MatchGlob("docs/**/*.md", "docs/sub/dir/a.md"))
+	require.False(t, MatchGlob("docs/**/*.md", "other/a.md"))
+	require.True(t, MatchGlob("**/*.pdf", "assets/a.pdf"))
+	require.False(t, MatchGlob("[", "["))
+}
@@ -15,7 +15,7 @@ func UnmarshalYAMLInterface(b []byte, i *interface{}) error {
 		if err != nil {
 			return err
 		}
-		*i = convertYAMLValue(s)
+		*i = ConvertYAMLValue(s)
 	default:
 		if err != nil {
 			return err
@@ -28,23 +28,24 @@ func UnmarshalYAMLInterface(b []byte, i *interface{}) error {
 			if err != nil {
 				return err
 			}
-			*i = convertYAMLValue(s)
+			*i = ConvertYAMLValue(s)
 		} else {
-			*i = convertYAMLValue(m)
+			*i = ConvertYAMLValue(m)
 		}
 	}
 	return nil
 }
 
-// convertYAMLValue recursively converts yaml.MapSlice and map[interface{}]interface{}
-// to map[string]interface{} and processes nested structures
-func convertYAMLValue(v interface{}) interface{} {
+// ConvertYAMLValue recursively converts yaml.MapSlice and map[interface{}]interface{}
+// to map[string]interface{} and processes nested structures. The result can
+// be marshaled as JSON.
+func ConvertYAMLValue(v interface{}) interface{} {
 	switch v := v.(type) {
 	case yaml.MapSlice:
 		m := make(map[string]interface{}, len(v))
 		for _, item := range v {
 			if key, ok := item.Key.(string); ok {
-				m[key] = convertYAMLValue(item.Value)
+				m[key] = ConvertYAMLValue(item.Value)
 			}
 		}
 		return m
@@ -52,14 +53,14 @@ func convertYAMLValue(v interface{}) interface{} {
 		m := make(map[string]interface{}, len(v))
 		for key, value := range v {
 			if keyStr, ok := key.(string); ok {
-				m[keyStr] = convertYAMLValue(value)
+				m[keyStr] = ConvertYAMLValue(value)
 			}
 		}
 		return m
 	case []interface{}:
 		result := make([]interface{}, len(v))
 		for i, item := range v {
-			result[i] = convertYAMLValue(item)
+			result[i] = ConvertYAMLValue(item)
 		}
 		return result
 	default: